@@ -0,0 +1,131 @@
+/*
+ * Copyright 2019 lostvip
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// lv_gen 根据数据库表结构生成 model、查询dto、mapper sql 和 gin handler
+//
+// 在项目根目录执行，数据源读取 application.yml 中的 application.datasource 配置：
+//
+//	go run github.com/lostvip-com/lv_framework/cmd/lv_gen -ds db-sys -tables sys_user,sys_role -out ./app
+//
+// 默认模板见 lv_db/lv_codegen/templates，可通过 -tpl 指定目录覆盖同名模板
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_db"
+	"github.com/lostvip-com/lv_framework/lv_db/lv_codegen"
+)
+
+func main() {
+	ds := flag.String("ds", "", "datasource name, default application.datasource.default")
+	tables := flag.String("tables", "", "comma separated table names, empty for all tables")
+	out := flag.String("out", ".", "output directory")
+	module := flag.String("module", "", "go import path of the output directory, default read from go.mod")
+	tplDir := flag.String("tpl", "", "directory of custom templates")
+	overwrite := flag.Bool("overwrite", false, "overwrite existing files")
+	flag.Parse()
+
+	lv_conf.RegisterCfg(new(lv_conf.CfgDefault))
+	if *ds == "" {
+		*ds = lv_conf.Config().GetDatasourceDefault()
+	}
+	if *ds == "" {
+		exit(fmt.Errorf("no datasource configured, use -ds"))
+	}
+	if *module == "" {
+		m, err := resolveModule(*out)
+		if err != nil {
+			exit(err)
+		}
+		*module = m
+	}
+
+	db := lv_db.GetDB(*ds)
+	names := make([]string, 0)
+	if *tables != "" {
+		names = strings.Split(*tables, ",")
+	}
+	list, err := lv_codegen.LoadTables(db, names...)
+	if err != nil {
+		exit(err)
+	}
+	gen := &lv_codegen.Generator{
+		Driver:    db.Dialector.Name(),
+		OutDir:    *out,
+		Module:    *module,
+		TplDir:    *tplDir,
+		Overwrite: *overwrite,
+	}
+	files, err := gen.Generate(list)
+	for _, f := range files {
+		fmt.Println("generated: " + f)
+	}
+	if err != nil {
+		exit(err)
+	}
+	lv_db.ShutdownDatabase()
+}
+
+// resolveModule 向上查找go.mod，拼接输出目录对应的包路径
+func resolveModule(out string) (string, error) {
+	absOut, err := filepath.Abs(out)
+	if err != nil {
+		return "", err
+	}
+	for dir := absOut; ; dir = filepath.Dir(dir) {
+		if name := readModuleName(filepath.Join(dir, "go.mod")); name != "" {
+			rel, err := filepath.Rel(dir, absOut)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return name, nil
+			}
+			return name + "/" + filepath.ToSlash(rel), nil
+		}
+		if dir == filepath.Dir(dir) {
+			return "", fmt.Errorf("go.mod not found, use -module")
+		}
+	}
+}
+
+func readModuleName(goMod string) string {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		}
+	}
+	return ""
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "lv_gen:", err)
+	os.Exit(1)
+}
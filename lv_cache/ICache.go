@@ -17,17 +17,20 @@
 package lv_cache
 
 import (
+	"context"
 	"time"
 
 	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
 	"github.com/lostvip-com/lv_framework/lv_cache/lv_redis"
 	"github.com/lostvip-com/lv_framework/lv_conf"
 	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
 )
 
-// 支持set、hashSet操作
+// 支持字符串、hash、计数、列表、集合、有序集合操作，内存与redis实现的行为一致
 type ICache interface {
 	Set(key string, value interface{}, expiration time.Duration) error
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error) //key不存在时才写入，返回是否写入
 	Get(key string) (value string, err error)
 	Del(key ...string) error
 
@@ -40,7 +43,32 @@ type ICache interface {
 	Close() error
 	Expire(key string, duration time.Duration) error
 	CountKeysByPattern(pattern string) (int64, error)
-	GetKeysPage(pattern string, page int, pageSize int) (keys []string, total int, err error)
+	GetKeysPage(pattern string, page int, pageSize int) (keys []string, total int, err error) //按key字典序分页，需要读取全部匹配的key
+	// ScanPage 游标分页，cursor 为空时从头开始，返回的 next 为空表示已遍历完
+	ScanPage(ctx context.Context, pattern, cursor string, count int) (keys []string, next string, err error)
+	// ScanKeys 遍历匹配的key，支持redis的全部匹配规则，fn 返回false时停止
+	ScanKeys(ctx context.Context, pattern string, fn func(key string) bool) error
+	DelByPattern(ctx context.Context, pattern string) (int64, error)
+
+	Incr(key string) (int64, error)
+	IncrBy(key string, value int64) (int64, error)
+	Decr(key string) (int64, error)
+	TTL(key string) (time.Duration, error)  //key不存在返回-2，永不过期返回-1
+	PTTL(key string) (time.Duration, error) //毫秒精度的剩余时间，返回值同TTL
+	Type(key string) (string, error)        //与redis的TYPE一致，返回 string/hash/list/set/zset，key不存在返回none
+
+	LPush(key string, values ...interface{}) (int64, error)
+	RPush(key string, values ...interface{}) (int64, error)
+	LPop(key string) (string, error)
+	RPop(key string) (string, error)
+	LRange(key string, start, stop int64) ([]string, error)
+
+	SAdd(key string, members ...interface{}) (int64, error)
+	SMembers(key string) ([]string, error)
+	SIsMember(key string, member interface{}) (bool, error)
+
+	ZAdd(key string, score float64, member string) (int64, error)
+	ZRangeByScore(key string, min, max string) ([]string, error)
 }
 
 var cacheClient ICache = nil //主数据库
@@ -48,12 +76,60 @@ var cacheClient ICache = nil //主数据库
 func GetCacheClient() ICache {
 	if cacheClient == nil {
 		var config = lv_conf.Config()
-		var cacheType = config.GetVipperCfg().GetString(lv_global.KEY_CACHE_TYPE)
+		var cacheType = config.GetValueStr(lv_global.KEY_CACHE_TYPE)
 		if cacheType == "redis" {
 			cacheClient = lv_redis.GetInstance(0)
+		} else if cacheType == "multi" { //本地内存 + redis 二级缓存
+			redisClient := lv_redis.GetInstance(0)
+			multi := NewMultiLevelCacheFromConf(redisClient)
+			multi.SetBus(NewRedisBus(redisClient, ""))
+			cacheClient = multi
 		} else {
-			cacheClient = lv_ram.GetRamCacheClient()
+			ram := lv_ram.GetRamCacheClient()
+			enableRamSnapshot(ram)
+			if config.GetBool(lv_global.KEY_CACHE_BROADCAST) { //多实例部署，写操作通知其它实例
+				cacheClient = NewBroadcastRamCache(ram, NewRedisBus(lv_redis.GetInstance(0), ""))
+			} else {
+				cacheClient = ram
+			}
+		}
+		if config.GetBool(lv_global.KEY_CACHE_METRICS + ".enabled") {
+			cacheClient = NewInstrumentedCache(cacheClient, nil)
 		}
 	}
 	return cacheClient
 }
+
+// CloseCacheClient 关闭已创建的缓存客户端，内存缓存开启快照时会在关闭前保存
+func CloseCacheClient() error {
+	if cacheClient == nil {
+		return nil
+	}
+	err := cacheClient.Close()
+	cacheClient = nil
+	return err
+}
+
+// enableRamSnapshot 按 application.cache.snapshot 配置恢复并定时保存内存缓存，重启后会话等数据不丢失
+//
+//	application:
+//	  cache:
+//	    snapshot:
+//	      enabled: true
+//	      path: data/cache.snapshot
+//	      interval: 5m
+func enableRamSnapshot(ram *lv_ram.RamCacheClient) {
+	if !lv_conf.Config().GetBool(lv_global.KEY_CACHE_SNAPSHOT + ".enabled") {
+		return
+	}
+	path := lv_conf.Config().GetValueStr(lv_global.KEY_CACHE_SNAPSHOT + ".path")
+	if path == "" {
+		path = "data/cache.snapshot"
+	}
+	count, err := ram.EnableSnapshot(path, lv_conf.Config().GetDuration(lv_global.KEY_CACHE_SNAPSHOT+".interval", 5*time.Minute))
+	if err != nil {
+		lv_log.Error("load cache snapshot error:", path, err)
+		return
+	}
+	lv_log.Info("cache snapshot restored:", path, count)
+}
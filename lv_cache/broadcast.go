@@ -0,0 +1,153 @@
+package lv_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/satori/go.uuid"
+)
+
+// BroadcastRamCache 多实例各自使用内存缓存时，写操作通知其它实例清除同名key，避免读到旧数据
+// 其它实例清除后重新从数据源加载，例如配合 GetOrLoad 使用
+type BroadcastRamCache struct {
+	*lv_ram.RamCacheClient
+	id          string
+	bus         InvalidationBus
+	unsubscribe func()
+}
+
+// NewBroadcastRamCache 创建并订阅失效通知
+func NewBroadcastRamCache(ram *lv_ram.RamCacheClient, bus InvalidationBus) *BroadcastRamCache {
+	c := &BroadcastRamCache{RamCacheClient: ram, id: uuid.NewV4().String(), bus: bus}
+	c.unsubscribe = bus.Subscribe(func(msg *InvalidateMessage) {
+		if msg.Source != c.id {
+			ram.Del(msg.Keys...)
+			for _, pattern := range msg.Patterns {
+				ram.DelByPattern(context.Background(), pattern)
+			}
+		}
+	})
+	return c
+}
+
+func (c *BroadcastRamCache) Set(key string, value interface{}, expiration time.Duration) error {
+	err := c.RamCacheClient.Set(key, value, expiration)
+	c.publish(key)
+	return err
+}
+
+func (c *BroadcastRamCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	ok, err := c.RamCacheClient.SetNX(key, value, expiration)
+	if ok {
+		c.publish(key)
+	}
+	return ok, err
+}
+
+func (c *BroadcastRamCache) Del(keys ...string) error {
+	err := c.RamCacheClient.Del(keys...)
+	c.publish(keys...)
+	return err
+}
+
+func (c *BroadcastRamCache) Expire(key string, duration time.Duration) error {
+	err := c.RamCacheClient.Expire(key, duration)
+	c.publish(key)
+	return err
+}
+
+func (c *BroadcastRamCache) HSet(key string, values ...interface{}) error {
+	err := c.RamCacheClient.HSet(key, values...)
+	c.publish(key)
+	return err
+}
+
+func (c *BroadcastRamCache) HMSet(key string, mp map[string]any, duration time.Duration) error {
+	err := c.RamCacheClient.HMSet(key, mp, duration)
+	c.publish(key)
+	return err
+}
+
+func (c *BroadcastRamCache) HDel(key string, fields ...string) error {
+	err := c.RamCacheClient.HDel(key, fields...)
+	c.publish(key)
+	return err
+}
+
+func (c *BroadcastRamCache) Incr(key string) (int64, error) {
+	return c.IncrBy(key, 1)
+}
+
+func (c *BroadcastRamCache) IncrBy(key string, value int64) (int64, error) {
+	n, err := c.RamCacheClient.IncrBy(key, value)
+	c.publish(key)
+	return n, err
+}
+
+func (c *BroadcastRamCache) Decr(key string) (int64, error) {
+	return c.IncrBy(key, -1)
+}
+
+func (c *BroadcastRamCache) LPush(key string, values ...interface{}) (int64, error) {
+	n, err := c.RamCacheClient.LPush(key, values...)
+	c.publish(key)
+	return n, err
+}
+
+func (c *BroadcastRamCache) RPush(key string, values ...interface{}) (int64, error) {
+	n, err := c.RamCacheClient.RPush(key, values...)
+	c.publish(key)
+	return n, err
+}
+
+func (c *BroadcastRamCache) LPop(key string) (string, error) {
+	v, err := c.RamCacheClient.LPop(key)
+	c.publish(key)
+	return v, err
+}
+
+func (c *BroadcastRamCache) RPop(key string) (string, error) {
+	v, err := c.RamCacheClient.RPop(key)
+	c.publish(key)
+	return v, err
+}
+
+func (c *BroadcastRamCache) SAdd(key string, members ...interface{}) (int64, error) {
+	n, err := c.RamCacheClient.SAdd(key, members...)
+	c.publish(key)
+	return n, err
+}
+
+func (c *BroadcastRamCache) ZAdd(key string, score float64, member string) (int64, error) {
+	n, err := c.RamCacheClient.ZAdd(key, score, member)
+	c.publish(key)
+	return n, err
+}
+
+func (c *BroadcastRamCache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	n, err := c.RamCacheClient.DelByPattern(ctx, pattern)
+	if e := c.bus.Publish(&InvalidateMessage{Source: c.id, Patterns: []string{pattern}}); e != nil {
+		lv_log.Error("publish invalidate message error:", pattern, e)
+	}
+	return n, err
+}
+
+// Close 取消订阅并关闭通知总线，再关闭内存缓存
+func (c *BroadcastRamCache) Close() error {
+	c.unsubscribe()
+	if err := c.bus.Close(); err != nil {
+		lv_log.Error("close invalidation bus error:", err)
+	}
+	return c.RamCacheClient.Close()
+}
+
+func (c *BroadcastRamCache) publish(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := c.bus.Publish(&InvalidateMessage{Source: c.id, Keys: keys}); err != nil {
+		lv_log.Error("publish invalidate message error:", keys, err)
+	}
+}
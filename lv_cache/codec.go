@@ -0,0 +1,97 @@
+package lv_cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec 缓存值的序列化方式，GetT/SetT 通过它编解码缓存值
+type Codec interface {
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	JSON    Codec = jsonCodec{}
+	Msgpack Codec = msgpackCodec{}
+	Gob     Codec = gobCodec{}
+)
+
+var (
+	codecs       = map[string]Codec{JSON.Name(): JSON, Msgpack.Name(): Msgpack, Gob.Name(): Gob}
+	codecsLock   sync.RWMutex
+	defaultCodec Codec
+)
+
+// RegisterCodec 注册自定义序列化方式，同名覆盖
+func RegisterCodec(codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	codecs[strings.ToLower(codec.Name())] = codec
+}
+
+// GetCodec 按名称获取序列化方式，不存在时返回nil
+func GetCodec(name string) Codec {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	return codecs[strings.ToLower(name)]
+}
+
+// SetDefaultCodec 设置默认序列化方式，优先于配置文件
+func SetDefaultCodec(codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	defaultCodec = codec
+}
+
+// DefaultCodec 默认序列化方式，读取 application.cache.codec，未配置时使用json
+func DefaultCodec() Codec {
+	codecsLock.RLock()
+	codec := defaultCodec
+	codecsLock.RUnlock()
+	if codec != nil {
+		return codec
+	}
+	if name := lv_conf.Config().GetValueStr(lv_global.KEY_CACHE_CODEC); name != "" {
+		if codec = GetCodec(name); codec != nil {
+			return codec
+		}
+	}
+	return JSON
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                       { return "json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string                       { return "msgpack" }
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,240 @@
+package lv_cache
+
+import (
+	"context"
+	"time"
+)
+
+// InstrumentedCache 为任意 ICache 实现记录命中、未命中和耗时，统计写入 CacheMetrics
+type InstrumentedCache struct {
+	ICache
+	metrics *CacheMetrics
+}
+
+// NewInstrumentedCache 包装缓存实现，metrics 为nil时使用全局的 Metrics()
+func NewInstrumentedCache(cache ICache, metrics *CacheMetrics) *InstrumentedCache {
+	if metrics == nil {
+		metrics = defaultMetrics
+	}
+	return &InstrumentedCache{ICache: cache, metrics: metrics}
+}
+
+// Unwrap 返回被包装的缓存实现
+func (c *InstrumentedCache) Unwrap() ICache {
+	return c.ICache
+}
+
+// unwrapCache 去掉统计等包装，得到实际的缓存实现，用于按类型选择redis专用的实现
+func unwrapCache(cache ICache) ICache {
+	for {
+		u, ok := cache.(interface{ Unwrap() ICache })
+		if !ok {
+			return cache
+		}
+		cache = u.Unwrap()
+	}
+}
+
+func (c *InstrumentedCache) read(key string, start time.Time, hit bool, err error) {
+	c.metrics.ObserveRead(key, time.Since(start), hit, err)
+}
+
+func (c *InstrumentedCache) write(key string, start time.Time, err error) {
+	c.metrics.ObserveWrite(key, time.Since(start), err)
+}
+
+func (c *InstrumentedCache) Get(key string) (string, error) {
+	start := time.Now()
+	v, err := c.ICache.Get(key)
+	c.read(key, start, err == nil, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) HGet(key, field string) (string, error) {
+	start := time.Now()
+	v, err := c.ICache.HGet(key, field)
+	c.read(key, start, err == nil, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) HGetAll(key string) (map[string]string, error) {
+	start := time.Now()
+	v, err := c.ICache.HGetAll(key)
+	c.read(key, start, len(v) > 0, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) Exists(key string) (int64, error) {
+	start := time.Now()
+	n, err := c.ICache.Exists(key)
+	if n == 0 { //内存实现不存在时返回错误，不计为错误
+		c.read(key, start, false, nil)
+	} else {
+		c.read(key, start, true, err)
+	}
+	return n, err
+}
+
+func (c *InstrumentedCache) TTL(key string) (time.Duration, error) {
+	start := time.Now()
+	d, err := c.ICache.TTL(key)
+	c.read(key, start, d != -2, err)
+	return d, err
+}
+
+func (c *InstrumentedCache) PTTL(key string) (time.Duration, error) {
+	start := time.Now()
+	d, err := c.ICache.PTTL(key)
+	c.read(key, start, d != -2, err)
+	return d, err
+}
+
+func (c *InstrumentedCache) Type(key string) (string, error) {
+	start := time.Now()
+	t, err := c.ICache.Type(key)
+	c.read(key, start, t != "none", err)
+	return t, err
+}
+
+func (c *InstrumentedCache) LPop(key string) (string, error) {
+	start := time.Now()
+	v, err := c.ICache.LPop(key)
+	c.read(key, start, err == nil, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) RPop(key string) (string, error) {
+	start := time.Now()
+	v, err := c.ICache.RPop(key)
+	c.read(key, start, err == nil, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) LRange(key string, start, stop int64) ([]string, error) {
+	begin := time.Now()
+	v, err := c.ICache.LRange(key, start, stop)
+	c.read(key, begin, len(v) > 0, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) SMembers(key string) ([]string, error) {
+	start := time.Now()
+	v, err := c.ICache.SMembers(key)
+	c.read(key, start, len(v) > 0, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) SIsMember(key string, member interface{}) (bool, error) {
+	start := time.Now()
+	ok, err := c.ICache.SIsMember(key, member)
+	c.read(key, start, ok, err)
+	return ok, err
+}
+
+func (c *InstrumentedCache) ZRangeByScore(key string, min, max string) ([]string, error) {
+	start := time.Now()
+	v, err := c.ICache.ZRangeByScore(key, min, max)
+	c.read(key, start, len(v) > 0, err)
+	return v, err
+}
+
+func (c *InstrumentedCache) Set(key string, value interface{}, expiration time.Duration) error {
+	start := time.Now()
+	err := c.ICache.Set(key, value, expiration)
+	c.write(key, start, err)
+	return err
+}
+
+func (c *InstrumentedCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	start := time.Now()
+	ok, err := c.ICache.SetNX(key, value, expiration)
+	c.write(key, start, err)
+	return ok, err
+}
+
+func (c *InstrumentedCache) Del(keys ...string) error {
+	start := time.Now()
+	err := c.ICache.Del(keys...)
+	for _, key := range keys {
+		c.write(key, start, err)
+	}
+	return err
+}
+
+func (c *InstrumentedCache) HSet(key string, values ...interface{}) error {
+	start := time.Now()
+	err := c.ICache.HSet(key, values...)
+	c.write(key, start, err)
+	return err
+}
+
+func (c *InstrumentedCache) HMSet(key string, mp map[string]any, duration time.Duration) error {
+	start := time.Now()
+	err := c.ICache.HMSet(key, mp, duration)
+	c.write(key, start, err)
+	return err
+}
+
+func (c *InstrumentedCache) HDel(key string, fields ...string) error {
+	start := time.Now()
+	err := c.ICache.HDel(key, fields...)
+	c.write(key, start, err)
+	return err
+}
+
+func (c *InstrumentedCache) Expire(key string, duration time.Duration) error {
+	start := time.Now()
+	err := c.ICache.Expire(key, duration)
+	c.write(key, start, err)
+	return err
+}
+
+func (c *InstrumentedCache) Incr(key string) (int64, error) {
+	return c.IncrBy(key, 1)
+}
+
+func (c *InstrumentedCache) Decr(key string) (int64, error) {
+	return c.IncrBy(key, -1)
+}
+
+func (c *InstrumentedCache) IncrBy(key string, value int64) (int64, error) {
+	start := time.Now()
+	n, err := c.ICache.IncrBy(key, value)
+	c.write(key, start, err)
+	return n, err
+}
+
+func (c *InstrumentedCache) LPush(key string, values ...interface{}) (int64, error) {
+	start := time.Now()
+	n, err := c.ICache.LPush(key, values...)
+	c.write(key, start, err)
+	return n, err
+}
+
+func (c *InstrumentedCache) RPush(key string, values ...interface{}) (int64, error) {
+	start := time.Now()
+	n, err := c.ICache.RPush(key, values...)
+	c.write(key, start, err)
+	return n, err
+}
+
+func (c *InstrumentedCache) SAdd(key string, members ...interface{}) (int64, error) {
+	start := time.Now()
+	n, err := c.ICache.SAdd(key, members...)
+	c.write(key, start, err)
+	return n, err
+}
+
+func (c *InstrumentedCache) ZAdd(key string, score float64, member string) (int64, error) {
+	start := time.Now()
+	n, err := c.ICache.ZAdd(key, score, member)
+	c.write(key, start, err)
+	return n, err
+}
+
+func (c *InstrumentedCache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	start := time.Now()
+	n, err := c.ICache.DelByPattern(ctx, pattern)
+	c.write(pattern, start, err)
+	return n, err
+}
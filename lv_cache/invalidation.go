@@ -0,0 +1,143 @@
+package lv_cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_redis"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_log"
+)
+
+// 失效通知频道前缀，后接应用名，不同应用共用一个redis时互不干扰
+const invalidateTopicPrefix = "lv_cache:invalidate:"
+
+// InvalidateMessage 失效通知，Source 为发送方标识，接收方据此忽略自己发出的通知
+type InvalidateMessage struct {
+	Source   string   `json:"source"`
+	Keys     []string `json:"keys"`
+	Patterns []string `json:"patterns,omitempty"` // 按匹配规则清除，见 DelByPattern
+}
+
+// InvalidationBus 本地缓存失效通知总线，某个实例修改数据后通知其它实例清除本地缓存
+type InvalidationBus interface {
+	Publish(msg *InvalidateMessage) error
+	// Subscribe 注册接收回调，返回取消订阅函数
+	Subscribe(fn func(msg *InvalidateMessage)) (unsubscribe func())
+	Close() error
+}
+
+// InvalidateTopic 当前应用的失效通知频道
+func InvalidateTopic() string {
+	return invalidateTopicPrefix + lv_conf.Config().GetAppName()
+}
+
+// subscribers 回调列表，LocalBus 与 RedisBus 共用
+type subscribers struct {
+	mu   sync.RWMutex
+	seq  int
+	subs map[int]func(msg *InvalidateMessage)
+}
+
+func (s *subscribers) add(fn func(msg *InvalidateMessage)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[int]func(msg *InvalidateMessage))
+	}
+	s.seq++
+	id := s.seq
+	s.subs[id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+func (s *subscribers) dispatch(msg *InvalidateMessage) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, fn := range s.subs {
+		fn(msg)
+	}
+}
+
+// LocalBus 进程内的失效通知，同步回调，用于测试或单进程多个缓存实例
+type LocalBus struct {
+	subscribers
+}
+
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+func (b *LocalBus) Publish(msg *InvalidateMessage) error {
+	b.dispatch(msg)
+	return nil
+}
+
+func (b *LocalBus) Subscribe(fn func(msg *InvalidateMessage)) func() {
+	return b.add(fn)
+}
+
+func (b *LocalBus) Close() error {
+	return nil
+}
+
+// RedisBus 基于redis发布订阅的失效通知
+type RedisBus struct {
+	subscribers
+	client *lv_redis.RedisClient
+	topic  string
+	cancel context.CancelFunc
+}
+
+// NewRedisBus 订阅 topic 并在后台接收通知，topic 为空时使用 InvalidateTopic()
+func NewRedisBus(client *lv_redis.RedisClient, topic string) *RedisBus {
+	if topic == "" {
+		topic = InvalidateTopic()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &RedisBus{client: client, topic: topic, cancel: cancel}
+	pubsub := client.Subscribe(ctx, topic)
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-ch:
+				if !ok {
+					return
+				}
+				msg := new(InvalidateMessage)
+				if err := json.Unmarshal([]byte(m.Payload), msg); err != nil {
+					lv_log.Error("invalidate message error:", m.Payload, err)
+					continue
+				}
+				b.dispatch(msg)
+			}
+		}
+	}()
+	return b
+}
+
+func (b *RedisBus) Publish(msg *InvalidateMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(context.Background(), b.topic, string(data))
+}
+
+func (b *RedisBus) Subscribe(fn func(msg *InvalidateMessage)) func() {
+	return b.add(fn)
+}
+
+func (b *RedisBus) Close() error {
+	b.cancel()
+	return nil
+}
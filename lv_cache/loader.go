@@ -0,0 +1,154 @@
+package lv_cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"golang.org/x/sync/singleflight"
+)
+
+// 缓存条目头部：魔数 + 逻辑过期时间(unix纳秒)，之后为序列化后的值
+const entryMagic = "lv1"
+const entryHeaderLen = len(entryMagic) + 8
+
+var errEntryFormat = errors.New("cache entry format error")
+
+var loadGroup singleflight.Group
+
+type loadOptions struct {
+	cache        ICache
+	codec        Codec
+	jitter       float64
+	refreshAhead time.Duration
+	staleTTL     time.Duration
+}
+
+// LoadOption GetOrLoad 的可选参数
+type LoadOption func(*loadOptions)
+
+// WithCache 指定缓存，默认 GetCacheClient()
+func WithCache(cache ICache) LoadOption {
+	return func(o *loadOptions) { o.cache = cache }
+}
+
+// WithCodec 指定序列化方式，默认 DefaultCodec()
+func WithCodec(codec Codec) LoadOption {
+	return func(o *loadOptions) { o.codec = codec }
+}
+
+// WithJitter 过期时间随机浮动比例，避免大量key同时过期，默认0.1即±10%
+func WithJitter(jitter float64) LoadOption {
+	return func(o *loadOptions) { o.jitter = jitter }
+}
+
+// WithEarlyRefresh 距离过期不足 ahead 时，返回缓存值的同时在后台刷新
+func WithEarlyRefresh(ahead time.Duration) LoadOption {
+	return func(o *loadOptions) { o.refreshAhead = ahead }
+}
+
+// WithStale 过期后 stale 时间内仍返回旧值，同时在后台刷新
+func WithStale(stale time.Duration) LoadOption {
+	return func(o *loadOptions) { o.staleTTL = stale }
+}
+
+// GetOrLoad 读取缓存，不存在时调用 loader 加载并写入缓存
+// 同一进程内相同key的并发加载只执行一次，其余调用等待并共享结果
+func GetOrLoad[T any](ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), opts ...LoadOption) (T, error) {
+	o := &loadOptions{jitter: 0.1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.cache == nil {
+		o.cache = GetCacheClient()
+	}
+	if o.codec == nil {
+		o.codec = DefaultCodec()
+	}
+
+	var value T
+	data, err := o.cache.Get(key)
+	if err == nil {
+		expireAt, payload, err := decodeEntry(data)
+		if err == nil && o.codec.Unmarshal(payload, &value) == nil {
+			now := time.Now()
+			if expireAt.IsZero() || now.Before(expireAt.Add(-o.refreshAhead)) {
+				return value, nil
+			}
+			if now.Before(expireAt.Add(o.staleTTL)) { //即将过期或已过期但仍在容忍时间内，后台刷新
+				go func() {
+					bgCtx := context.WithoutCancel(ctx)
+					if _, err := load(bgCtx, key, ttl, loader, o); err != nil {
+						lv_log.Error("cache refresh error:", key, err)
+					}
+				}()
+				return value, nil
+			}
+		}
+	} else if !IsNil(err) {
+		return value, err
+	}
+	return load(ctx, key, ttl, loader, o)
+}
+
+func load[T any](ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), o *loadOptions) (T, error) {
+	// 不同缓存实例、不同值类型的同名key不能共享加载结果
+	flightKey := fmt.Sprintf("%p|%s|%s", o.cache, reflect.TypeFor[T](), key)
+	v, err, _ := loadGroup.Do(flightKey, func() (interface{}, error) {
+		value, err := loader(ctx)
+		if err != nil {
+			return value, err
+		}
+		payload, err := o.codec.Marshal(value)
+		if err != nil {
+			return value, err
+		}
+		var expireAt time.Time //不过期时逻辑过期时间为零值
+		expiration := ttl
+		if ttl > 0 {
+			ttl = jitterTTL(ttl, o.jitter)
+			expireAt = time.Now().Add(ttl)
+			expiration = ttl + o.staleTTL
+		}
+		if err = o.cache.Set(key, encodeEntry(expireAt, payload), expiration); err != nil {
+			lv_log.Error("cache set error:", key, err)
+		}
+		return value, nil
+	})
+	value, _ := v.(T)
+	return value, err
+}
+
+// jitterTTL 在 ttl 基础上随机浮动 ±jitter 比例
+func jitterTTL(ttl time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	delta := time.Duration(float64(ttl) * jitter * (rand.Float64()*2 - 1))
+	return ttl + delta
+}
+
+func encodeEntry(expireAt time.Time, payload []byte) string {
+	buf := make([]byte, entryHeaderLen, entryHeaderLen+len(payload))
+	copy(buf, entryMagic)
+	if !expireAt.IsZero() {
+		binary.BigEndian.PutUint64(buf[len(entryMagic):], uint64(expireAt.UnixNano()))
+	}
+	return string(append(buf, payload...))
+}
+
+func decodeEntry(data string) (time.Time, []byte, error) {
+	if len(data) < entryHeaderLen || data[:len(entryMagic)] != entryMagic {
+		return time.Time{}, nil, errEntryFormat
+	}
+	nano := binary.BigEndian.Uint64([]byte(data[len(entryMagic):entryHeaderLen]))
+	if nano == 0 {
+		return time.Time{}, []byte(data[entryHeaderLen:]), nil
+	}
+	return time.Unix(0, int64(nano)), []byte(data[entryHeaderLen:]), nil
+}
@@ -0,0 +1,96 @@
+package lv_cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+)
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	c := lv_ram.NewRamCacheClient()
+	var calls int32
+	loader := func(ctx context.Context) (cacheUser, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return cacheUser{Id: 1, Name: "lv"}, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u, err := GetOrLoad(context.Background(), "sf:user", time.Minute, loader, WithCache(c), WithCodec(JSON))
+			if err != nil || u.Id != 1 {
+				t.Error(u, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("loader called %d times", calls)
+	}
+	if u, _ := GetOrLoad(context.Background(), "sf:user", time.Minute, loader, WithCache(c), WithCodec(JSON)); u.Name != "lv" || calls != 1 {
+		t.Fatal(u, calls)
+	}
+}
+
+func TestGetOrLoadSameKey(t *testing.T) {
+	c1, c2 := lv_ram.NewRamCacheClient(), lv_ram.NewRamCacheClient()
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		u, err := GetOrLoad(context.Background(), "same", time.Minute, func(ctx context.Context) (cacheUser, error) {
+			time.Sleep(50 * time.Millisecond)
+			return cacheUser{Id: 1}, nil
+		}, WithCache(c1), WithCodec(JSON))
+		if err != nil || u.Id != 1 {
+			t.Error(u, err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		n, err := GetOrLoad(context.Background(), "same", time.Minute, func(ctx context.Context) (int, error) {
+			time.Sleep(50 * time.Millisecond)
+			return 2, nil
+		}, WithCache(c1), WithCodec(Gob))
+		if err != nil || n != 2 {
+			t.Error(n, err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		u, err := GetOrLoad(context.Background(), "same", time.Minute, func(ctx context.Context) (cacheUser, error) {
+			time.Sleep(50 * time.Millisecond)
+			return cacheUser{Id: 3}, nil
+		}, WithCache(c2), WithCodec(JSON))
+		if err != nil || u.Id != 3 {
+			t.Error(u, err)
+		}
+	}()
+	wg.Wait()
+}
+
+func TestGetOrLoadStale(t *testing.T) {
+	c := lv_ram.NewRamCacheClient()
+	var version int32
+	loader := func(ctx context.Context) (int32, error) {
+		return atomic.AddInt32(&version, 1), nil
+	}
+	opts := []LoadOption{WithCache(c), WithCodec(Gob), WithJitter(0), WithStale(time.Minute)}
+	if v, _ := GetOrLoad(context.Background(), "stale", 20*time.Millisecond, loader, opts...); v != 1 {
+		t.Fatal(v)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if v, _ := GetOrLoad(context.Background(), "stale", 20*time.Millisecond, loader, opts...); v != 1 { //过期后先返回旧值
+		t.Fatal(v)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if v, _ := GetOrLoad(context.Background(), "stale", time.Minute, loader, opts...); v != 2 {
+		t.Fatal(v)
+	}
+}
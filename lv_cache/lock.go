@@ -0,0 +1,313 @@
+package lv_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_redis"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/satori/go.uuid"
+)
+
+var (
+	ErrNotObtained = errors.New("lock not obtained")
+	ErrLockNotHeld = errors.New("lock not held")
+	ErrLockTTL     = errors.New("lock ttl must be at least 1ms")
+)
+
+// 获取锁失败后的重试间隔
+const lockRetryInterval = 50 * time.Millisecond
+
+// 内存锁清理过期记录的间隔
+const ramLockSweepInterval = time.Minute
+
+// redis 防护令牌计数的保留时间，每次加锁时重置，长期不用的锁名不会一直占用key
+const lockFenceTTL = 7 * 24 * time.Hour
+
+// Locker 分布式锁的存储实现，value 为持有者标识，只有持有者才能续期和释放
+type Locker interface {
+	// TryLock 尝试加锁，成功时返回递增的防护令牌(fencing token)
+	TryLock(ctx context.Context, name, value string, ttl time.Duration) (token int64, ok bool, err error)
+	Refresh(ctx context.Context, name, value string, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context, name, value string) (bool, error)
+}
+
+// Lease 已获得的锁
+type Lease struct {
+	locker Locker
+	name   string
+	value  string
+	token  int64
+	ttl    time.Duration
+	stop   chan struct{}
+	once   sync.Once
+}
+
+type lockOptions struct {
+	locker    Locker
+	autoRenew bool
+}
+
+// LockOption Lock 的可选参数
+type LockOption func(*lockOptions)
+
+// WithLocker 指定锁的存储实现，默认按缓存类型选择redis或内存
+func WithLocker(locker Locker) LockOption {
+	return func(o *lockOptions) { o.locker = locker }
+}
+
+// WithAutoRenew 持有期间每 ttl/3 自动续期，直到 Unlock，用于执行时间不确定的长任务
+func WithAutoRenew() LockOption {
+	return func(o *lockOptions) { o.autoRenew = true }
+}
+
+// Lock 加锁，锁被占用时等待重试直到 ctx 结束，此时返回 ctx.Err()
+func Lock(ctx context.Context, name string, ttl time.Duration, opts ...LockOption) (*Lease, error) {
+	for {
+		lease, err := TryLock(ctx, name, ttl, opts...)
+		if !errors.Is(err, ErrNotObtained) {
+			return lease, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// TryLock 只尝试一次加锁，锁被占用时返回 ErrNotObtained
+func TryLock(ctx context.Context, name string, ttl time.Duration, opts ...LockOption) (*Lease, error) {
+	o := &lockOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if ttl < time.Millisecond {
+		return nil, ErrLockTTL
+	}
+	if o.locker == nil {
+		o.locker = GetLocker()
+	}
+	value := uuid.NewV4().String()
+	token, ok, err := o.locker.TryLock(ctx, name, value, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotObtained
+	}
+	lease := &Lease{locker: o.locker, name: name, value: value, token: token, ttl: ttl, stop: make(chan struct{})}
+	if o.autoRenew {
+		go lease.renew()
+	}
+	return lease, nil
+}
+
+// Name 锁名称
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// Token 防护令牌，同一个锁每次加锁递增，写入下游资源时携带，下游拒绝比已见过的更小的令牌
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// Refresh 续期，锁已过期或被他人持有时返回 ErrLockNotHeld
+func (l *Lease) Refresh(ctx context.Context, ttl time.Duration) error {
+	if ttl < time.Millisecond {
+		return ErrLockTTL
+	}
+	ok, err := l.locker.Refresh(ctx, l.name, l.value, ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Unlock 释放锁，只会删除自己持有的锁
+func (l *Lease) Unlock(ctx context.Context) error {
+	l.once.Do(func() { close(l.stop) })
+	ok, err := l.locker.Unlock(ctx, l.name, l.value)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+func (l *Lease) renew() {
+	interval := l.ttl / 3
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.Refresh(context.Background(), l.ttl); err != nil {
+				lv_log.Error("lock renew error:", l.name, err)
+				return
+			}
+		}
+	}
+}
+
+var (
+	ramLocker     *RamLocker
+	ramLockerOnce sync.Once
+)
+
+// GetLocker 按缓存类型选择锁的实现，使用redis时为分布式锁，否则为进程内锁
+func GetLocker() Locker {
+	switch c := unwrapCache(GetCacheClient()).(type) {
+	case *lv_redis.RedisClient:
+		return NewRedisLocker(c)
+	case *MultiLevelCache:
+		if rc, ok := c.Remote.(*lv_redis.RedisClient); ok {
+			return NewRedisLocker(rc)
+		}
+	}
+	ramLockerOnce.Do(func() {
+		ramLocker = NewRamLocker()
+	})
+	return ramLocker
+}
+
+// lock key 使用 {name} 作为hash tag，保证集群模式下锁与令牌计数在同一个slot
+func lockKey(name string) string {
+	return "lv_lock:{" + name + "}"
+}
+
+// fenceKey 防护令牌计数，保留 lockFenceTTL，过期后令牌重新从1开始
+func fenceKey(name string) string {
+	return "lv_lock:{" + name + "}:fence"
+}
+
+const lockScript = `
+if redis.call('set', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	local token = redis.call('incr', KEYS[2])
+	redis.call('pexpire', KEYS[2], ARGV[3])
+	return token
+end
+return 0`
+
+const refreshScript = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0`
+
+const unlockScript = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0`
+
+// RedisLocker 基于 SET NX PX 的redis锁，续期和释放通过lua脚本比较持有者后执行
+type RedisLocker struct {
+	client *lv_redis.RedisClient
+}
+
+func NewRedisLocker(client *lv_redis.RedisClient) *RedisLocker {
+	return &RedisLocker{client: client}
+}
+
+func (r *RedisLocker) TryLock(ctx context.Context, name, value string, ttl time.Duration) (int64, bool, error) {
+	if ttl < time.Millisecond {
+		return 0, false, ErrLockTTL
+	}
+	fenceTTL := max(lockFenceTTL, 2*ttl)
+	token, err := r.client.RunScript(ctx, lockScript, []string{lockKey(name), fenceKey(name)},
+		value, ttl.Milliseconds(), fenceTTL.Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+	return token, token > 0, nil
+}
+
+func (r *RedisLocker) Refresh(ctx context.Context, name, value string, ttl time.Duration) (bool, error) {
+	n, err := r.client.RunScript(ctx, refreshScript, []string{lockKey(name)}, value, ttl.Milliseconds()).Int64()
+	return n > 0, err
+}
+
+func (r *RedisLocker) Unlock(ctx context.Context, name, value string) (bool, error) {
+	n, err := r.client.RunScript(ctx, unlockScript, []string{lockKey(name)}, value).Int64()
+	return n > 0, err
+}
+
+type ramLock struct {
+	value    string
+	expireAt time.Time
+}
+
+// RamLocker 进程内锁，用于内存缓存模式，过期判断与redis一致
+// 防护令牌使用全局递增序号，同一个锁的令牌同样递增，不需要为每个锁保存计数
+type RamLocker struct {
+	mu        sync.Mutex
+	locks     map[string]*ramLock
+	token     int64
+	lastSweep time.Time
+}
+
+func NewRamLocker() *RamLocker {
+	return &RamLocker{locks: make(map[string]*ramLock), lastSweep: time.Now()}
+}
+
+func (r *RamLocker) TryLock(ctx context.Context, name, value string, ttl time.Duration) (int64, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.sweep(now)
+	if l, ok := r.locks[name]; ok && now.Before(l.expireAt) {
+		return 0, false, nil
+	}
+	r.locks[name] = &ramLock{value: value, expireAt: now.Add(ttl)}
+	r.token++
+	return r.token, true, nil
+}
+
+// sweep 定期删除已过期未释放的锁，调用方需持有 r.mu
+func (r *RamLocker) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < ramLockSweepInterval {
+		return
+	}
+	r.lastSweep = now
+	for name, l := range r.locks {
+		if !now.Before(l.expireAt) {
+			delete(r.locks, name)
+		}
+	}
+}
+
+func (r *RamLocker) Refresh(ctx context.Context, name, value string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.locks[name]
+	if !ok || l.value != value || !time.Now().Before(l.expireAt) {
+		return false, nil
+	}
+	l.expireAt = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (r *RamLocker) Unlock(ctx context.Context, name, value string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.locks[name]
+	if !ok || l.value != value {
+		return false, nil
+	}
+	delete(r.locks, name)
+	return time.Now().Before(l.expireAt), nil
+}
@@ -0,0 +1,69 @@
+package lv_cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRamLock(t *testing.T) {
+	ctx := context.Background()
+	locker := WithLocker(NewRamLocker())
+	a, err := TryLock(ctx, "job", 50*time.Millisecond, locker)
+	if err != nil || a.Token() != 1 {
+		t.Fatal(a, err)
+	}
+	if _, err = TryLock(ctx, "job", time.Second, locker); !errors.Is(err, ErrNotObtained) {
+		t.Fatal(err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	b, err := TryLock(ctx, "job", time.Second, locker) //a已过期
+	if err != nil || b.Token() != 2 {
+		t.Fatal(b, err)
+	}
+	if err = a.Refresh(ctx, time.Second); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatal(err)
+	}
+	if err = a.Unlock(ctx); !errors.Is(err, ErrLockNotHeld) { //不能释放别人的锁
+		t.Fatal(err)
+	}
+	if err = b.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = TryLock(ctx, "job", 500*time.Microsecond, locker); !errors.Is(err, ErrLockTTL) { //redis 的 PX 0 会报错
+		t.Fatal(err)
+	}
+}
+
+func TestRamLockSweep(t *testing.T) {
+	ctx := context.Background()
+	r := NewRamLocker()
+	if _, ok, _ := r.TryLock(ctx, "a", "v", time.Millisecond); !ok {
+		t.Fatal("not obtained")
+	}
+	time.Sleep(5 * time.Millisecond)
+	r.lastSweep = time.Now().Add(-ramLockSweepInterval)
+	token, ok, _ := r.TryLock(ctx, "b", "v", time.Second)
+	if !ok || token != 2 || len(r.locks) != 1 {
+		t.Fatal(token, r.locks)
+	}
+}
+
+func TestLockAutoRenew(t *testing.T) {
+	ctx := context.Background()
+	locker := WithLocker(NewRamLocker())
+	a, err := Lock(ctx, "renew", 30*time.Millisecond, locker, WithAutoRenew())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err = Lock(waitCtx, "renew", time.Second, locker); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if err = a.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
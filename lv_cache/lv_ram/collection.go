@@ -0,0 +1,369 @@
+package lv_ram
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// 与redis保持一致的错误
+var (
+	WrongType  = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	NotInteger = errors.New("ERR value is not an integer or out of range")
+	NotFloat   = errors.New("ERR min or max is not a float")
+)
+
+// 内存中的列表、集合、有序集合，与字符串/hash一样存放在 gocache 中
+type ramList struct {
+	items []string
+}
+
+type ramSet map[string]struct{}
+
+type ramZSet map[string]float64
+
+// getItem 读取key及其剩余过期时间，过期时间为 NoExpiration 表示永不过期
+func (rcc *RamCacheClient) getItem(key string) (interface{}, time.Duration, bool) {
+	v, expireAt, ok := rcc.c.GetWithExpiration(key)
+	if !ok {
+		return nil, 0, false
+	}
+	if expireAt.IsZero() {
+		return v, gocache.NoExpiration, true
+	}
+	return v, time.Until(expireAt), true
+}
+
+// IncrBy 与redis一致，key不存在时从0开始，不改变原有的过期时间
+func (rcc *RamCacheClient) IncrBy(key string, value int64) (int64, error) {
+	if err := rcc.invalidKey(key); err != nil {
+		return 0, err
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	var n int64
+	ttl := gocache.NoExpiration
+	if v, d, ok := rcc.getItem(key); ok {
+		str, isStr := v.(string)
+		if !isStr {
+			return 0, WrongType
+		}
+		var err error
+		if n, err = strconv.ParseInt(str, 10, 64); err != nil {
+			return 0, NotInteger
+		}
+		ttl = d
+	}
+	n += value
+	rcc.c.Set(key, strconv.FormatInt(n, 10), ttl)
+	return n, nil
+}
+
+func (rcc *RamCacheClient) Incr(key string) (int64, error) {
+	return rcc.IncrBy(key, 1)
+}
+
+func (rcc *RamCacheClient) Decr(key string) (int64, error) {
+	return rcc.IncrBy(key, -1)
+}
+
+// TTL 与redis一致，key不存在返回-2，永不过期返回-1
+func (rcc *RamCacheClient) TTL(key string) (time.Duration, error) {
+	_, d, ok := rcc.getItem(key)
+	if !ok {
+		return -2, nil
+	}
+	if d == gocache.NoExpiration {
+		return -1, nil
+	}
+	return d.Round(time.Second), nil
+}
+
+// PTTL 毫秒精度的TTL
+func (rcc *RamCacheClient) PTTL(key string) (time.Duration, error) {
+	_, d, ok := rcc.getItem(key)
+	if !ok {
+		return -2, nil
+	}
+	if d == gocache.NoExpiration {
+		return -1, nil
+	}
+	return d.Truncate(time.Millisecond), nil
+}
+
+// Type 与redis一致，返回 string/hash/list/set/zset，key不存在返回none
+func (rcc *RamCacheClient) Type(key string) (string, error) {
+	v, _, ok := rcc.getItem(key)
+	if !ok {
+		return "none", nil
+	}
+	switch v.(type) {
+	case string:
+		return "string", nil
+	case *gocache.Cache:
+		return "hash", nil
+	case *ramList:
+		return "list", nil
+	case ramSet:
+		return "set", nil
+	case ramZSet:
+		return "zset", nil
+	}
+	return "", WrongType
+}
+
+func (rcc *RamCacheClient) getList(key string) (*ramList, time.Duration, error) {
+	v, d, ok := rcc.getItem(key)
+	if !ok {
+		return nil, gocache.NoExpiration, nil
+	}
+	l, isList := v.(*ramList)
+	if !isList {
+		return nil, 0, WrongType
+	}
+	return l, d, nil
+}
+
+func (rcc *RamCacheClient) push(key string, left bool, values []interface{}) (int64, error) {
+	if err := rcc.invalidKey(key); err != nil {
+		return 0, err
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	l, ttl, err := rcc.getList(key)
+	if err != nil {
+		return 0, err
+	}
+	if l == nil {
+		l = &ramList{}
+	}
+	for _, v := range values {
+		str, err := marshalValue(v)
+		if err != nil {
+			return 0, err
+		}
+		if left {
+			l.items = append([]string{str}, l.items...)
+		} else {
+			l.items = append(l.items, str)
+		}
+	}
+	rcc.c.Set(key, l, ttl)
+	return int64(len(l.items)), nil
+}
+
+// LPush 依次插入到列表头部，返回列表长度
+func (rcc *RamCacheClient) LPush(key string, values ...interface{}) (int64, error) {
+	return rcc.push(key, true, values)
+}
+
+// RPush 依次追加到列表尾部，返回列表长度
+func (rcc *RamCacheClient) RPush(key string, values ...interface{}) (int64, error) {
+	return rcc.push(key, false, values)
+}
+
+func (rcc *RamCacheClient) pop(key string, left bool) (string, error) {
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	l, _, err := rcc.getList(key)
+	if err != nil {
+		return "", err
+	}
+	if l == nil || len(l.items) == 0 {
+		return "", Nil
+	}
+	var v string
+	if left {
+		v, l.items = l.items[0], l.items[1:]
+	} else {
+		v, l.items = l.items[len(l.items)-1], l.items[:len(l.items)-1]
+	}
+	if len(l.items) == 0 { //与redis一致，列表为空时删除key
+		rcc.c.Delete(key)
+	}
+	return v, nil
+}
+
+func (rcc *RamCacheClient) LPop(key string) (string, error) {
+	return rcc.pop(key, true)
+}
+
+func (rcc *RamCacheClient) RPop(key string) (string, error) {
+	return rcc.pop(key, false)
+}
+
+// LRange 返回 [start, stop] 区间的元素，负数表示从尾部倒数
+func (rcc *RamCacheClient) LRange(key string, start, stop int64) ([]string, error) {
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	l, _, err := rcc.getList(key)
+	if err != nil || l == nil {
+		return []string{}, err
+	}
+	n := int64(len(l.items))
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return []string{}, nil
+	}
+	return append([]string{}, l.items[start:stop+1]...), nil
+}
+
+func (rcc *RamCacheClient) getSet(key string) (ramSet, time.Duration, error) {
+	v, d, ok := rcc.getItem(key)
+	if !ok {
+		return nil, gocache.NoExpiration, nil
+	}
+	s, isSet := v.(ramSet)
+	if !isSet {
+		return nil, 0, WrongType
+	}
+	return s, d, nil
+}
+
+// SAdd 添加集合元素，返回新增的个数
+func (rcc *RamCacheClient) SAdd(key string, members ...interface{}) (int64, error) {
+	if err := rcc.invalidKey(key); err != nil {
+		return 0, err
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	s, ttl, err := rcc.getSet(key)
+	if err != nil {
+		return 0, err
+	}
+	if s == nil {
+		s = make(ramSet)
+	}
+	var added int64
+	for _, m := range members {
+		str, err := marshalValue(m)
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := s[str]; !ok {
+			s[str] = struct{}{}
+			added++
+		}
+	}
+	rcc.c.Set(key, s, ttl)
+	return added, nil
+}
+
+func (rcc *RamCacheClient) SMembers(key string) ([]string, error) {
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	s, _, err := rcc.getSet(key)
+	if err != nil {
+		return nil, err
+	}
+	arr := make([]string, 0, len(s))
+	for m := range s {
+		arr = append(arr, m)
+	}
+	return arr, nil
+}
+
+func (rcc *RamCacheClient) SIsMember(key string, member interface{}) (bool, error) {
+	str, err := marshalValue(member)
+	if err != nil {
+		return false, err
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	s, _, err := rcc.getSet(key)
+	if err != nil {
+		return false, err
+	}
+	_, ok := s[str]
+	return ok, nil
+}
+
+// ZAdd 添加或更新有序集合元素，返回新增的个数
+func (rcc *RamCacheClient) ZAdd(key string, score float64, member string) (int64, error) {
+	if err := rcc.invalidKey(key); err != nil {
+		return 0, err
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	v, ttl, ok := rcc.getItem(key)
+	z := make(ramZSet)
+	if ok {
+		var isZSet bool
+		if z, isZSet = v.(ramZSet); !isZSet {
+			return 0, WrongType
+		}
+	} else {
+		ttl = gocache.NoExpiration
+	}
+	_, exist := z[member]
+	z[member] = score
+	rcc.c.Set(key, z, ttl)
+	if exist {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// ZRangeByScore 按分值从小到大返回，min/max 与redis相同，支持 -inf/+inf 和 ( 开区间
+func (rcc *RamCacheClient) ZRangeByScore(key string, min, max string) ([]string, error) {
+	lo, loOpen, err := parseScore(min)
+	if err != nil {
+		return nil, err
+	}
+	hi, hiOpen, err := parseScore(max)
+	if err != nil {
+		return nil, err
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	v, _, ok := rcc.getItem(key)
+	if !ok {
+		return []string{}, nil
+	}
+	z, isZSet := v.(ramZSet)
+	if !isZSet {
+		return nil, WrongType
+	}
+	arr := make([]string, 0)
+	for m, s := range z {
+		if (s > lo || (!loOpen && s == lo)) && (s < hi || (!hiOpen && s == hi)) {
+			arr = append(arr, m)
+		}
+	}
+	sort.Slice(arr, func(i, j int) bool { //分值相同时按成员字典序，与redis一致
+		if z[arr[i]] != z[arr[j]] {
+			return z[arr[i]] < z[arr[j]]
+		}
+		return arr[i] < arr[j]
+	})
+	return arr, nil
+}
+
+func parseScore(s string) (float64, bool, error) {
+	open := strings.HasPrefix(s, "(")
+	s = strings.TrimPrefix(s, "(")
+	switch strings.ToLower(s) {
+	case "-inf":
+		return math.Inf(-1), open, nil
+	case "+inf", "inf":
+		return math.Inf(1), open, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, NotFloat
+	}
+	return f, open, nil
+}
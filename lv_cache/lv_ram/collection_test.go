@@ -0,0 +1,113 @@
+package lv_ram
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRamCounterTTL(t *testing.T) {
+	c := NewRamCacheClient()
+	if ttl, _ := c.TTL("cnt"); ttl != -2 {
+		t.Fatal(ttl)
+	}
+	if n, _ := c.Incr("cnt"); n != 1 {
+		t.Fatal(n)
+	}
+	if ttl, _ := c.TTL("cnt"); ttl != -1 {
+		t.Fatal(ttl)
+	}
+	c.Expire("cnt", 10*time.Second)
+	if n, _ := c.IncrBy("cnt", 5); n != 6 {
+		t.Fatal(n)
+	}
+	if ttl, _ := c.TTL("cnt"); ttl != 10*time.Second { //自增不改变过期时间
+		t.Fatal(ttl)
+	}
+	c.Set("str", "abc", 0)
+	if _, err := c.Decr("str"); !errors.Is(err, NotInteger) {
+		t.Fatal(err)
+	}
+	if ttl, _ := c.TTL("str"); ttl != -1 { //与redis一致，过期时间为0时永不过期
+		t.Fatal(ttl)
+	}
+	if err := c.Expire("str", 0); err != nil { //与redis一致，过期时间为0时删除
+		t.Fatal(err)
+	}
+	if n, _ := c.Exists("str"); n != 0 {
+		t.Fatal(n)
+	}
+	if err := c.Expire("str", time.Second); !errors.Is(err, Nil) {
+		t.Fatal(err)
+	}
+	c.HMSet("hash", map[string]any{"a": "1", "b": "2"}, 10*time.Second)
+	c.HSet("hash", "c", "3") //不改变过期时间
+	if ttl, _ := c.TTL("hash"); ttl != 10*time.Second {
+		t.Fatal(ttl)
+	}
+	if m, _ := c.HGetAll("hash"); len(m) != 3 {
+		t.Fatal(m)
+	}
+}
+
+func TestRamCollections(t *testing.T) {
+	c := NewRamCacheClient()
+	c.RPush("list", "b", "c")
+	c.LPush("list", "a")
+	if arr, _ := c.LRange("list", 0, -1); !reflect.DeepEqual(arr, []string{"a", "b", "c"}) {
+		t.Fatal(arr)
+	}
+	if arr, _ := c.LRange("list", -2, 10); !reflect.DeepEqual(arr, []string{"b", "c"}) {
+		t.Fatal(arr)
+	}
+	if v, _ := c.RPop("list"); v != "c" {
+		t.Fatal(v)
+	}
+	c.LPop("list")
+	c.LPop("list")
+	if _, err := c.LPop("list"); !errors.Is(err, Nil) {
+		t.Fatal(err)
+	}
+	if ttl, _ := c.TTL("list"); ttl != -2 { //列表为空时删除
+		t.Fatal(ttl)
+	}
+
+	if n, _ := c.SAdd("set", "x", "y", "x"); n != 2 {
+		t.Fatal(n)
+	}
+	members, _ := c.SMembers("set")
+	sort.Strings(members)
+	if !reflect.DeepEqual(members, []string{"x", "y"}) {
+		t.Fatal(members)
+	}
+	if ok, _ := c.SIsMember("set", "z"); ok {
+		t.Fatal("z")
+	}
+	c.SAdd("flags", true, []byte("raw"), 1.5) //与redis写入的格式一致
+	members, _ = c.SMembers("flags")
+	sort.Strings(members)
+	if !reflect.DeepEqual(members, []string{"1", "1.5", "raw"}) {
+		t.Fatal(members)
+	}
+	if ok, _ := c.SIsMember("flags", "1"); !ok {
+		t.Fatal("true")
+	}
+	if _, err := c.Get("set"); !errors.Is(err, WrongType) {
+		t.Fatal(err)
+	}
+
+	c.ZAdd("z", 3, "c")
+	c.ZAdd("z", 1, "b")
+	c.ZAdd("z", 1, "a")
+	if n, _ := c.ZAdd("z", 2, "c"); n != 0 {
+		t.Fatal(n)
+	}
+	if arr, _ := c.ZRangeByScore("z", "-inf", "+inf"); !reflect.DeepEqual(arr, []string{"a", "b", "c"}) {
+		t.Fatal(arr)
+	}
+	if arr, _ := c.ZRangeByScore("z", "(1", "2"); !reflect.DeepEqual(arr, []string{"c"}) {
+		t.Fatal(arr)
+	}
+}
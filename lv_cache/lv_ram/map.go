@@ -17,10 +17,13 @@
 package lv_ram
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	gocache "github.com/patrickmn/go-cache"
@@ -41,7 +44,11 @@ var (
 )
 
 type RamCacheClient struct {
-	c *gocache.Cache
+	c  *gocache.Cache
+	mu sync.Mutex // 计数、列表、集合等读改写操作加锁，保证与redis一样是原子的
+
+	snapshotPath string        // 快照文件，为空时不保存快照
+	stopSnapshot chan struct{} // 停止定时保存快照
 }
 
 func GetRamCacheClient() *RamCacheClient {
@@ -51,29 +58,39 @@ func GetRamCacheClient() *RamCacheClient {
 	return rdb
 }
 
+// NewRamCacheClient 过期时间与redis一致，写入时 expiration 为0表示永不过期
 func NewRamCacheClient() *RamCacheClient {
 	return &RamCacheClient{
-		c: gocache.New(30*time.Minute, 5*time.Minute),
+		c: gocache.New(gocache.NoExpiration, 5*time.Minute),
 	}
 }
 
+// Expire 与redis一致，duration 小于等于0时删除key，key不存在时返回 Nil
 func (rcc *RamCacheClient) Expire(key string, duration time.Duration) error {
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	return rcc.expire(key, duration)
+}
+
+func (rcc *RamCacheClient) expire(key string, duration time.Duration) error {
 	val, exist := rcc.c.Get(key)
-	if exist {
-		rcc.c.Set(key, val, duration)
+	if !exist {
+		return Nil
+	}
+	if duration <= 0 {
+		rcc.c.Delete(key)
 		return nil
-	} else {
-		return errors.New(key + "不存在")
 	}
+	rcc.c.Set(key, val, duration)
+	return nil
 }
 
+// Exists 与redis一致，返回存在的key数量
 func (rcc *RamCacheClient) Exists(key string) (int64, error) {
-	_, exist := rcc.c.Get(key)
-	if exist {
+	if _, exist := rcc.c.Get(key); exist {
 		return 1, nil
-	} else {
-		return 0, errors.New(key + "不存在")
 	}
+	return 0, nil
 }
 
 func (rcc *RamCacheClient) Set(key string, value interface{}, expiration time.Duration) error {
@@ -84,20 +101,41 @@ func (rcc *RamCacheClient) Set(key string, value interface{}, expiration time.Du
 	if err = rcc.invalidKeyValue(key, val); err != nil {
 		return err
 	}
+	rcc.mu.Lock() //expiration 为0时永不过期
 	rcc.c.Set(key, val, expiration)
+	rcc.mu.Unlock()
 	return nil
 }
 
+// SetNX 与redis一致，key不存在时才写入
+func (rcc *RamCacheClient) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	val, err := marshalValue(value)
+	if err != nil {
+		return false, err
+	}
+	if err = rcc.invalidKeyValue(key, val); err != nil {
+		return false, err
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	return rcc.c.Add(key, val, expiration) == nil, nil
+}
+
 func (rcc *RamCacheClient) Get(key string) (data string, err error) {
 	value, exist := rcc.c.Get(key)
 	if !exist {
 		return "", Nil
 	}
-	data = value.(string)
+	data, ok := value.(string)
+	if !ok {
+		return "", WrongType
+	}
 	return
 }
 
 func (rcc *RamCacheClient) Del(keys ...string) error {
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
 	for _, key := range keys {
 		rcc.c.Delete(key)
 	}
@@ -112,17 +150,30 @@ func (rcc *RamCacheClient) getHashCache(key string) (*gocache.Cache, bool, error
 	if !exist {
 		return nil, false, nil
 	}
-	value := data.(*gocache.Cache)
+	value, ok := data.(*gocache.Cache)
+	if !ok {
+		return nil, false, WrongType
+	}
 	return value, true, nil
 }
 
 func (rcc *RamCacheClient) HSet(key string, values ...interface{}) error {
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	return rcc.hset(key, values...)
+}
+
+// hset 调用方需持有 rcc.mu，已存在的hash保留原有的过期时间
+func (rcc *RamCacheClient) hset(key string, values ...interface{}) error {
 	hashCache, exist, err := rcc.getHashCache(key)
 	if err != nil {
 		return err
 	}
+	ttl := gocache.DefaultExpiration
 	if !exist {
 		hashCache = gocache.New(gocache.DefaultExpiration, gocache.DefaultExpiration)
+	} else if _, d, ok := rcc.getItem(key); ok {
+		ttl = d
 	}
 	var field, val string
 	for i := 0; i < len(values); i += 2 {
@@ -132,7 +183,7 @@ func (rcc *RamCacheClient) HSet(key string, values ...interface{}) error {
 		case string:
 			field = values[i].(string)
 		case map[string]any:
-			return rcc.HMSet(key, values[i].(map[string]any), gocache.DefaultExpiration)
+			return rcc.hmset(key, values[i].(map[string]any), 0)
 		default:
 			err = HashSetFieldTypeError
 			return err
@@ -152,7 +203,7 @@ func (rcc *RamCacheClient) HSet(key string, values ...interface{}) error {
 		}
 		hashCache.Set(field, val, gocache.DefaultExpiration)
 	}
-	rcc.c.Set(key, hashCache, gocache.DefaultExpiration)
+	rcc.c.Set(key, hashCache, ttl)
 	return nil
 }
 
@@ -173,6 +224,8 @@ func (rcc *RamCacheClient) HGet(key, field string) (string, error) {
 }
 
 func (rcc *RamCacheClient) HDel(key string, fields ...string) error {
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
 	hashCache, exist, err := rcc.getHashCache(key)
 	if err != nil {
 		return err
@@ -201,26 +254,47 @@ func (rcc *RamCacheClient) HGetAll(key string) (map[string]string, error) {
 	return mp, nil
 }
 
+// Close 清空缓存，开启了快照时先停止定时保存并保存最后一次快照
 func (rcc *RamCacheClient) Close() error {
+	err := rcc.closeSnapshot()
 	rcc.c.Flush()
-	return nil
+	return err
 }
 
 // ///////////////////////////////////////////////////////////////////////////////////////////////
+// marshalValue 与redis客户端写入的格式一致，基本类型转为字符串，结构体等转为json
 func marshalValue(value interface{}) (string, error) {
-	if value == nil {
+	switch v := value.(type) {
+	case nil:
 		return "", ValueNull
-	}
-	switch value.(type) {
 	case string:
-		return value.(string), nil
-	default:
-		data, err := json.Marshal(value)
-		if err != nil {
-			return "", err
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		if v {
+			return "1", nil
 		}
-		return string(data), nil
+		return "0", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10), nil
+	case encoding.BinaryMarshaler:
+		data, err := v.MarshalBinary()
+		return string(data), err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
 	}
+	return string(data), nil
 }
 
 func (rcc *RamCacheClient) invalidKey(key string) error {
@@ -258,102 +332,21 @@ func (rcc *RamCacheClient) invalidFieldValue(field, value string) error {
 	return rcc.invalidValue(value)
 }
 
+// HMSet 与redis一致，duration 大于0时设置整个hash的过期时间
 func (rcc *RamCacheClient) HMSet(pk string, m map[string]any, duration time.Duration) error {
-	for k, v := range m {
-		rcc.HSet(pk, k, v)
-		rcc.Expire(pk+":"+k, duration)
-	}
-	return nil
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	return rcc.hmset(pk, m, duration)
 }
 
-// 支持reids的key匹配规则
-func (rcc *RamCacheClient) CountKeysByPattern(pattern string) (int64, error) {
-	if pattern == "" {
-		return 0, KeyNull
-	}
-
-	var count int64 = 0
-
-	// 遍历内存中的所有键
-	for key := range rcc.c.Items() {
-		// 检查键是否匹配模式
-		if matchPattern(key, pattern) {
-			count++
-		}
-	}
-
-	return count, nil
-}
-
-// matchPattern 检查键是否匹配模式
-func matchPattern(key, pattern string) bool {
-	// 支持 * 和 ?
-	return matchPatternRecursive(key, pattern)
-}
-
-// matchPatternRecursive 递归匹配模式
-func matchPatternRecursive(key, pattern string) bool {
-	if pattern == "" {
-		return key == ""
-	}
-
-	// 检查第一个字符
-	switch pattern[0] {
-	case '*':
-		// * 匹配任意数量的字符
-		for len(key) >= 0 {
-			if matchPatternRecursive(key, pattern[1:]) {
-				return true
-			}
-			key = key[1:]
-		}
-		return false
-	case '?':
-		// ? 匹配任意单个字符
-		if len(key) == 0 {
-			return false
-		}
-		return matchPatternRecursive(key[1:], pattern[1:])
-	default:
-		// 普通字符匹配
-		if len(key) == 0 || key[0] != pattern[0] {
-			return false
-		}
-		return matchPatternRecursive(key[1:], pattern[1:])
-	}
-}
-
-func (rcc *RamCacheClient) GetKeysPage(pattern string, page int, pageSize int) (keys []string, total int, err error) {
-	if pattern == "" {
-		return nil, 0, KeyNull
-	}
-
-	var allKeys []string
-
-	// 遍历内存中的所有键，收集匹配的键
-	for key := range rcc.c.Items() {
-		if matchPattern(key, pattern) {
-			allKeys = append(allKeys, key)
+func (rcc *RamCacheClient) hmset(pk string, m map[string]any, duration time.Duration) error {
+	for k, v := range m {
+		if err := rcc.hset(pk, k, v); err != nil {
+			return err
 		}
 	}
-
-	total = len(allKeys)
-	if total == 0 {
-		return []string{}, 0, nil
-	}
-
-	start := (page - 1) * pageSize
-	if start < 0 {
-		start = 0
-	}
-	end := start + pageSize
-	if end > total {
-		end = total
+	if duration > 0 {
+		return rcc.expire(pk, duration)
 	}
-
-	if start >= total {
-		return []string{}, total, nil
-	}
-
-	return allKeys[start:end], total, nil
+	return nil
 }
@@ -0,0 +1,194 @@
+package lv_ram
+
+import (
+	"context"
+	"sort"
+)
+
+// MatchPattern 与redis KEYS/SCAN 相同的glob匹配规则，按字节匹配
+// * 任意个字符，? 单个字符，[abc] [^abc] [a-z] 字符集合，\ 转义下一个字符
+func MatchPattern(pattern, key string) bool {
+	p, k := 0, 0
+	starP, starK := -1, 0
+	for k < len(key) {
+		if p < len(pattern) {
+			if pattern[p] == '*' {
+				for p < len(pattern) && pattern[p] == '*' {
+					p++
+				}
+				if p == len(pattern) {
+					return true
+				}
+				starP, starK = p, k
+				continue
+			}
+			if width, ok := matchOne(pattern, p, key[k]); ok {
+				p += width
+				k++
+				continue
+			}
+		}
+		if starP < 0 { //没有可以回溯的 *
+			return false
+		}
+		starK++
+		p, k = starP, starK
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchOne 匹配单个字符，返回模式中消耗的长度
+func matchOne(pattern string, p int, c byte) (int, bool) {
+	switch pattern[p] {
+	case '?':
+		return 1, true
+	case '\\':
+		if p+1 < len(pattern) {
+			return 2, pattern[p+1] == c
+		}
+		return 1, c == '\\'
+	case '[':
+		return matchClass(pattern, p, c)
+	}
+	return 1, pattern[p] == c
+}
+
+// matchClass 匹配 [...] 字符集合，缺少 ] 时与redis一样视为到模式结尾
+func matchClass(pattern string, p int, c byte) (int, bool) {
+	i := p + 1
+	not := i < len(pattern) && pattern[i] == '^'
+	if not {
+		i++
+	}
+	match := false
+	for i < len(pattern) && pattern[i] != ']' {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			if pattern[i] == c {
+				match = true
+			}
+		case i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']':
+			start, end := pattern[i], pattern[i+2]
+			if start > end {
+				start, end = end, start
+			}
+			if c >= start && c <= end {
+				match = true
+			}
+			i += 2
+		case pattern[i] == c:
+			match = true
+		}
+		i++
+	}
+	if i < len(pattern) { //跳过 ]
+		i++
+	}
+	return i - p, match != not
+}
+
+// matchKeys 当前未过期且匹配的key，按字典序排列
+func (rcc *RamCacheClient) matchKeys(pattern string) []string {
+	keys := make([]string, 0)
+	for key := range rcc.c.Items() {
+		if MatchPattern(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ScanKeys 遍历匹配的key，fn 返回false时停止遍历
+func (rcc *RamCacheClient) ScanKeys(ctx context.Context, pattern string, fn func(key string) bool) error {
+	if pattern == "" {
+		return KeyNull
+	}
+	for _, key := range rcc.matchKeys(pattern) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(key) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// DelByPattern 删除匹配的key，返回删除的数量
+func (rcc *RamCacheClient) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	if pattern == "" {
+		return 0, KeyNull
+	}
+	keys := rcc.matchKeys(pattern)
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	var count int64
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		if _, ok := rcc.c.Get(key); ok { //匹配之后可能已被删除或过期
+			rcc.c.Delete(key)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountKeysByPattern 支持redis的key匹配规则
+func (rcc *RamCacheClient) CountKeysByPattern(pattern string) (int64, error) {
+	if pattern == "" {
+		return 0, KeyNull
+	}
+	return int64(len(rcc.matchKeys(pattern))), nil
+}
+
+// ScanPage 按字典序游标分页，cursor 为上一页返回的 next，为空时从头开始；next 为空表示已遍历完
+func (rcc *RamCacheClient) ScanPage(ctx context.Context, pattern, cursor string, count int) (keys []string, next string, err error) {
+	if pattern == "" {
+		return nil, "", KeyNull
+	}
+	if count <= 0 {
+		count = 10
+	}
+	all := rcc.matchKeys(pattern)
+	start := sort.SearchStrings(all, cursor)
+	if start < len(all) && cursor != "" && all[start] == cursor {
+		start++
+	}
+	end := start + count
+	if end >= len(all) {
+		return all[start:], "", nil
+	}
+	return all[start:end], all[end-1], nil
+}
+
+// GetKeysPage 按字典序分页，total 为匹配的key总数，page 从1开始
+func (rcc *RamCacheClient) GetKeysPage(pattern string, page int, pageSize int) (keys []string, total int, err error) {
+	if pattern == "" {
+		return nil, 0, KeyNull
+	}
+	all := rcc.matchKeys(pattern)
+	return PageKeys(all, page, pageSize), len(all), nil
+}
+
+// PageKeys 对已排序的key分页，内存与redis实现共用
+func PageKeys(keys []string, page int, pageSize int) []string {
+	start := (page - 1) * pageSize
+	if start < 0 {
+		start = 0
+	}
+	if start >= len(keys) || pageSize <= 0 {
+		return []string{}
+	}
+	end := start + pageSize
+	if end > len(keys) {
+		end = len(keys)
+	}
+	return keys[start:end]
+}
@@ -0,0 +1,64 @@
+package lv_ram
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"*", "", true},
+		{"user:*", "user:1", true},
+		{"user:*", "role:1", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[b-a]llo", "hallo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{`h[\]]llo`, "h]llo", true},
+		{"*:1:*", "a:1:b:1:c", true},
+		{"a*b*c", "abxbxc", true},
+		{"a*b*c", "abxbx", false},
+		{"a[bc", "ab", true}, //缺少 ] 时匹配到模式结尾
+	}
+	for _, tc := range cases {
+		if got := MatchPattern(tc.pattern, tc.key); got != tc.want {
+			t.Errorf("MatchPattern(%q, %q) = %v", tc.pattern, tc.key, got)
+		}
+	}
+}
+
+func TestScanKeysPage(t *testing.T) {
+	c := NewRamCacheClient()
+	for _, k := range []string{"u:3", "u:1", "u:2", "r:1"} {
+		c.Set(k, "v", 0)
+	}
+	keys, total, err := c.GetKeysPage("u:*", 2, 2)
+	if err != nil || total != 3 || !reflect.DeepEqual(keys, []string{"u:3"}) {
+		t.Fatal(keys, total, err)
+	}
+	page, next, err := c.ScanPage(context.Background(), "u:*", "", 2)
+	if err != nil || next != "u:2" || !reflect.DeepEqual(page, []string{"u:1", "u:2"}) {
+		t.Fatal(page, next, err)
+	}
+	if page, next, _ = c.ScanPage(context.Background(), "u:*", next, 2); next != "" || !reflect.DeepEqual(page, []string{"u:3"}) {
+		t.Fatal(page, next)
+	}
+	n, err := c.DelByPattern(context.Background(), "u:[12]")
+	if err != nil || n != 2 {
+		t.Fatal(n, err)
+	}
+	if n, _ := c.CountKeysByPattern("*"); n != 2 {
+		t.Fatal(n)
+	}
+}
@@ -0,0 +1,216 @@
+package lv_ram
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_log"
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// 快照中的数据类型
+const (
+	snapshotString = "string"
+	snapshotHash   = "hash"
+	snapshotList   = "list"
+	snapshotSet    = "set"
+	snapshotZSet   = "zset"
+)
+
+// 快照使用gob编码，值按原始字节保存，二进制数据(如msgpack、gob序列化的值)不会被改写
+const snapshotVersion = 2
+
+var errSnapshotVersion = errors.New("unsupported snapshot version")
+
+type snapshotFile struct {
+	Version int
+	Time    time.Time
+	Entries []snapshotEntry
+}
+
+// snapshotEntry 一个key，ExpireAt 为过期时间的unix纳秒，0为永不过期
+type snapshotEntry struct {
+	Key      string
+	Type     string
+	ExpireAt int64
+	Value    string
+	Fields   []snapshotField    // hash
+	Items    []string           // list、set
+	Scores   map[string]float64 // zset
+}
+
+// snapshotField hash中的字段，Name 为内部存储的完整名称 key:field
+type snapshotField struct {
+	Name     string
+	Value    string
+	ExpireAt int64
+}
+
+// SaveSnapshot 将未过期的数据及过期时间保存到文件，先写临时文件再替换，避免写到一半时进程退出
+func (rcc *RamCacheClient) SaveSnapshot(path string) error {
+	rcc.mu.Lock()
+	items := rcc.c.Items()
+	rcc.mu.Unlock()
+	snap := snapshotFile{Version: snapshotVersion, Time: time.Now(), Entries: make([]snapshotEntry, 0, len(items))}
+	for key, item := range items {
+		e := snapshotEntry{Key: key, ExpireAt: item.Expiration}
+		switch v := item.Object.(type) {
+		case string:
+			e.Type, e.Value = snapshotString, v
+		case *gocache.Cache:
+			e.Type = snapshotHash
+			for name, field := range v.Items() {
+				if str, ok := field.Object.(string); ok {
+					e.Fields = append(e.Fields, snapshotField{Name: name, Value: str, ExpireAt: field.Expiration})
+				}
+			}
+		case *ramList:
+			rcc.mu.Lock()
+			e.Type, e.Items = snapshotList, append([]string{}, v.items...)
+			rcc.mu.Unlock()
+		case ramSet:
+			e.Type = snapshotSet
+			rcc.mu.Lock()
+			for m := range v {
+				e.Items = append(e.Items, m)
+			}
+			rcc.mu.Unlock()
+		case ramZSet:
+			e.Type, e.Scores = snapshotZSet, make(map[string]float64, len(v))
+			rcc.mu.Lock()
+			for m, score := range v {
+				e.Scores[m] = score
+			}
+			rcc.mu.Unlock()
+		default: //其它类型不是通过RamCacheClient写入的，无法还原
+			continue
+		}
+		snap.Entries = append(snap.Entries, e)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) //可能包含会话数据，只允许本用户读写
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(f).Encode(&snap)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadSnapshot 从快照文件恢复数据，已过期的key被忽略，文件不存在时返回0
+func (rcc *RamCacheClient) LoadSnapshot(path string) (int, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var snap snapshotFile
+	if err = gob.NewDecoder(f).Decode(&snap); err != nil {
+		return 0, err
+	}
+	if snap.Version != snapshotVersion {
+		return 0, fmt.Errorf("%w: %d", errSnapshotVersion, snap.Version)
+	}
+	rcc.mu.Lock()
+	defer rcc.mu.Unlock()
+	count := 0
+	for _, e := range snap.Entries {
+		ttl, alive := snapshotTTL(e.ExpireAt)
+		if !alive {
+			continue
+		}
+		var obj interface{}
+		switch e.Type {
+		case snapshotString:
+			obj = e.Value
+		case snapshotHash:
+			hashCache := gocache.New(gocache.DefaultExpiration, gocache.DefaultExpiration)
+			for _, f := range e.Fields {
+				if d, ok := snapshotTTL(f.ExpireAt); ok {
+					hashCache.Set(f.Name, f.Value, d)
+				}
+			}
+			obj = hashCache
+		case snapshotList:
+			obj = &ramList{items: e.Items}
+		case snapshotSet:
+			s := make(ramSet, len(e.Items))
+			for _, m := range e.Items {
+				s[m] = struct{}{}
+			}
+			obj = s
+		case snapshotZSet:
+			obj = ramZSet(e.Scores)
+		default:
+			continue
+		}
+		rcc.c.Set(e.Key, obj, ttl)
+		count++
+	}
+	return count, nil
+}
+
+// snapshotTTL 由过期时间计算剩余时间，返回false表示已过期
+func snapshotTTL(expireAt int64) (time.Duration, bool) {
+	if expireAt == 0 {
+		return gocache.NoExpiration, true
+	}
+	d := time.Until(time.Unix(0, expireAt))
+	return d, d > 0
+}
+
+// EnableSnapshot 启动时从快照恢复，之后每隔 interval 保存一次，Close 时再保存一次
+// interval 为0时只在 Close 时保存
+func (rcc *RamCacheClient) EnableSnapshot(path string, interval time.Duration) (int, error) {
+	count, err := rcc.LoadSnapshot(path)
+	rcc.snapshotPath = path
+	if interval > 0 {
+		rcc.stopSnapshot = make(chan struct{})
+		go rcc.runSnapshot(path, interval, rcc.stopSnapshot)
+	}
+	return count, err
+}
+
+func (rcc *RamCacheClient) runSnapshot(path string, interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := rcc.SaveSnapshot(path); err != nil {
+				lv_log.Error("lv_ram save snapshot error:", path, err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// closeSnapshot 停止定时保存并保存最后一次快照
+func (rcc *RamCacheClient) closeSnapshot() error {
+	if rcc.stopSnapshot != nil {
+		close(rcc.stopSnapshot)
+		rcc.stopSnapshot = nil
+	}
+	if rcc.snapshotPath == "" {
+		return nil
+	}
+	path := rcc.snapshotPath
+	rcc.snapshotPath = ""
+	return rcc.SaveSnapshot(path)
+}
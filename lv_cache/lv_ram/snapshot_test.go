@@ -0,0 +1,72 @@
+package lv_ram
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	c := NewRamCacheClient()
+	c.Set("str", "v", time.Hour)
+	c.Set("gone", "v", time.Millisecond)
+	c.HSet("hash", "a", "1", "b", "2")
+	c.RPush("list", "x", "y")
+	c.SAdd("set", "m")
+	c.ZAdd("zset", 1.5, "m")
+	if _, err := c.EnableSnapshot(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRamCacheClient()
+	n, err := r.LoadSnapshot(path)
+	if err != nil || n != 5 {
+		t.Fatal(n, err)
+	}
+	if ttl, _ := r.TTL("str"); ttl <= 59*time.Minute {
+		t.Fatal(ttl)
+	}
+	if m, _ := r.HGetAll("hash"); !reflect.DeepEqual(m, map[string]string{"a": "1", "b": "2"}) {
+		t.Fatal(m)
+	}
+	if l, _ := r.LRange("list", 0, -1); !reflect.DeepEqual(l, []string{"x", "y"}) {
+		t.Fatal(l)
+	}
+	if ok, _ := r.SIsMember("set", "m"); !ok {
+		t.Fatal("set")
+	}
+	if z, _ := r.ZRangeByScore("zset", "-inf", "+inf"); !reflect.DeepEqual(z, []string{"m"}) {
+		t.Fatal(z)
+	}
+}
+
+func TestSnapshotBinary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	bin := string([]byte{0x82, 0xa1, 0xff, 0x00, 0xc3, 0x28}) //非UTF-8字节，例如msgpack序列化的值
+	c := NewRamCacheClient()
+	c.Set("bin", bin, time.Hour)
+	c.HSet("hash", "f", bin)
+	c.RPush("list", bin)
+	if err := c.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRamCacheClient()
+	if _, err := r.LoadSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := r.Get("bin"); v != bin {
+		t.Fatalf("%x", v)
+	}
+	if v, _ := r.HGet("hash", "f"); v != bin {
+		t.Fatalf("%x", v)
+	}
+	if l, _ := r.LRange("list", 0, -1); !reflect.DeepEqual(l, []string{bin}) {
+		t.Fatal(l)
+	}
+}
@@ -0,0 +1,105 @@
+package lv_redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func (rcc *RedisClient) Incr(key string) (int64, error) {
+	return rcc.client.Incr(context.Background(), key).Result()
+}
+
+func (rcc *RedisClient) IncrBy(key string, value int64) (int64, error) {
+	return rcc.client.IncrBy(context.Background(), key, value).Result()
+}
+
+func (rcc *RedisClient) Decr(key string) (int64, error) {
+	return rcc.client.Decr(context.Background(), key).Result()
+}
+
+// TTL key不存在返回-2，永不过期返回-1
+func (rcc *RedisClient) TTL(key string) (time.Duration, error) {
+	return rcc.client.TTL(context.Background(), key).Result()
+}
+
+// PTTL 毫秒精度的TTL
+func (rcc *RedisClient) PTTL(key string) (time.Duration, error) {
+	return rcc.client.PTTL(context.Background(), key).Result()
+}
+
+// Type key不存在返回none
+func (rcc *RedisClient) Type(key string) (string, error) {
+	return rcc.client.Type(context.Background(), key).Result()
+}
+
+func (rcc *RedisClient) LPush(key string, values ...interface{}) (int64, error) {
+	values, err := marshalValues(values)
+	if err != nil {
+		return 0, err
+	}
+	return rcc.client.LPush(context.Background(), key, values...).Result()
+}
+
+func (rcc *RedisClient) RPush(key string, values ...interface{}) (int64, error) {
+	values, err := marshalValues(values)
+	if err != nil {
+		return 0, err
+	}
+	return rcc.client.RPush(context.Background(), key, values...).Result()
+}
+
+func (rcc *RedisClient) LPop(key string) (string, error) {
+	return rcc.client.LPop(context.Background(), key).Result()
+}
+
+func (rcc *RedisClient) RPop(key string) (string, error) {
+	return rcc.client.RPop(context.Background(), key).Result()
+}
+
+func (rcc *RedisClient) LRange(key string, start, stop int64) ([]string, error) {
+	return rcc.client.LRange(context.Background(), key, start, stop).Result()
+}
+
+func (rcc *RedisClient) SAdd(key string, members ...interface{}) (int64, error) {
+	members, err := marshalValues(members)
+	if err != nil {
+		return 0, err
+	}
+	return rcc.client.SAdd(context.Background(), key, members...).Result()
+}
+
+func (rcc *RedisClient) SMembers(key string) ([]string, error) {
+	return rcc.client.SMembers(context.Background(), key).Result()
+}
+
+func (rcc *RedisClient) SIsMember(key string, member interface{}) (bool, error) {
+	member, err := MarshalValue(member)
+	if err != nil {
+		return false, err
+	}
+	return rcc.client.SIsMember(context.Background(), key, member).Result()
+}
+
+func (rcc *RedisClient) ZAdd(key string, score float64, member string) (int64, error) {
+	return rcc.client.ZAdd(context.Background(), key, redis.Z{Score: score, Member: member}).Result()
+}
+
+// ZRangeByScore min/max 支持 -inf/+inf 和 ( 开区间
+func (rcc *RedisClient) ZRangeByScore(key string, min, max string) ([]string, error) {
+	return rcc.client.ZRangeByScore(context.Background(), key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+}
+
+// marshalValues 列表、集合的元素与 Set 的值使用相同的编码
+func marshalValues(values []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		data, err := MarshalValue(v)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = data
+	}
+	return result, nil
+}
@@ -18,24 +18,89 @@ package lv_redis
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/lostvip-com/lv_framework/lv_conf"
 	"github.com/lostvip-com/lv_framework/lv_log"
 	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cast"
+)
+
+// redis 配置前缀，命名实例为 application.redis.<name>，与数据源的配置方式一致
+const redisConfPrefix = "application.redis"
+
+// 部署模式
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
 )
 
 var (
 	redisClient *RedisClient
 	onceRedis   = sync.Once{}
+	namedRedis  sync.Map
+	namedLock   sync.Mutex
 )
 
 type RedisClient struct {
-	client *redis.Client
+	client redis.UniversalClient
+	mode   string
 }
 
+// RedisConfig redis连接配置
+//
+//	application:
+//	  redis:
+//	    mode: standalone        # standalone/sentinel/cluster，不配置时按 master-name、addrs 推断
+//	    host: 127.0.0.1
+//	    port: 6379
+//	    addrs: 10.0.0.1:6379,10.0.0.2:6379  # 哨兵或集群节点
+//	    master-name: mymaster
+//	    username:
+//	    password:
+//	    db: 0
+//	    pool-size: 20
+//	    min-idle-conns: 2
+//	    dial-timeout: 5s
+//	    read-timeout: 3s
+//	    write-timeout: 3s
+//	    tls:
+//	      enabled: true
+//	      ca: /etc/redis/ca.pem
+//	      cert: /etc/redis/client.pem
+//	      key: /etc/redis/client.key
+//	      server-name:
+//	      insecure-skip-verify: false
+//	    session:                # 命名实例，使用 GetNamedInstance("session") 获取
+//	      host: 127.0.0.1
+//	      port: 6380
+type RedisConfig struct {
+	Mode             string
+	Addrs            []string
+	MasterName       string
+	Username         string
+	Password         string
+	SentinelPassword string
+	DB               int
+	PoolSize         int
+	MinIdleConns     int
+	DialTimeout      time.Duration
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+	TLS              *tls.Config
+}
+
+// GetInstance 默认实例，application.redis.default 指定了实例名时使用该命名实例
 func GetInstance(indexDb int) *RedisClient {
 	if redisClient == nil {
 		onceRedis.Do(func() {
@@ -45,33 +110,182 @@ func GetInstance(indexDb int) *RedisClient {
 	return redisClient
 }
 
+// GetNamedInstance 获取 application.redis.<name> 配置的命名实例
+func GetNamedInstance(name string) *RedisClient {
+	if c, ok := namedRedis.Load(name); ok {
+		return c.(*RedisClient)
+	}
+	namedLock.Lock()
+	defer namedLock.Unlock()
+	if c, ok := namedRedis.Load(name); ok {
+		return c.(*RedisClient)
+	}
+	cfg, err := LoadRedisConfig(redisConfPrefix + "." + name)
+	if err != nil {
+		panic(fmt.Sprintf("Redis[%s] 配置错误: %v", name, err))
+	}
+	client := mustConnect(name, cfg)
+	namedRedis.Store(name, client)
+	return client
+}
+
+// NewRedisClient 按默认配置创建客户端，indexDb 大于0时覆盖配置的db，连接失败时panic
 func NewRedisClient(indexDb int) *RedisClient {
-	conf := lv_conf.CfgDefault{}
-	addr := conf.GetValueStr("application.redis.host")
-	port := conf.GetValueStr("application.redis.port")
-	password := conf.GetValueStr("application.redis.password")
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr + ":" + port,
-		Password: password, // 没有密码，默认值
-		DB:       indexDb,  // 默认DB 0
-	})
-
-	redisClient := &RedisClient{client: rdb}
+	prefix := redisConfPrefix
+	name := lv_conf.Config().GetValueStr(redisConfPrefix + ".default")
+	if name != "" {
+		prefix = prefix + "." + name
+	}
+	cfg, err := LoadRedisConfig(prefix)
+	if err != nil {
+		panic(fmt.Sprintf("Redis 配置错误: %v", err))
+	}
+	if indexDb > 0 {
+		cfg.DB = indexDb
+	}
+	if name == "" {
+		name = "default"
+	}
+	return mustConnect(name, cfg)
+}
+
+func mustConnect(name string, cfg *RedisConfig) *RedisClient {
+	client, err := NewRedisClientWithConfig(cfg)
+	if err != nil { // 不输出密码
+		lv_log.Error(fmt.Sprintf("------------>连接 Redis[%s] 错误，mode: %s addrs: %v err: %v", name, cfg.mode(), cfg.Addrs, err))
+		panic(fmt.Sprintf("Redis 错误: mode: %s addrs: %v", cfg.mode(), cfg.Addrs))
+	}
+	return client
+}
 
+// NewRedisClientWithConfig 按配置创建单机、哨兵或集群客户端并测试连接
+// 连接失败时同时返回客户端和错误，调用方可决定是否继续使用；mode 不正确时客户端为nil
+func NewRedisClientWithConfig(cfg *RedisConfig) (*RedisClient, error) {
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		TLSConfig:        cfg.TLS,
+	}
+	rcc := &RedisClient{mode: cfg.mode()}
+	switch rcc.mode {
+	case ModeCluster:
+		rcc.client = redis.NewClusterClient(opts.Cluster())
+	case ModeSentinel:
+		rcc.client = redis.NewFailoverClient(opts.Failover())
+	case ModeStandalone:
+		rcc.client = redis.NewClient(opts.Simple())
+	default:
+		return nil, fmt.Errorf("unknown redis mode: %s", rcc.mode)
+	}
 	// 测试连接
-	if _, err := redisClient.Ping(context.Background()); err != nil {
-		msg := `
-              ------------>连接 Redis 错误：
-              无法链接到 Redis!!!! 检查相关配置:
-              host: %v
-              port: %v
-              password: %v
-             `
-		lv_log.Error(fmt.Sprintf(msg, addr, port, password))
-		panic(fmt.Sprintf("Redis 错误: host: %v port: %v", addr, port))
+	_, err := rcc.Ping(context.Background())
+	return rcc, err
+}
+
+// LoadRedisConfig 从已注册的配置中读取 prefix 下的redis配置，支持 ${ENV:default} 表达式
+func LoadRedisConfig(prefix string) (*RedisConfig, error) {
+	conf := lv_conf.Config()
+	get := func(key string) string {
+		return conf.GetValueStr(prefix + "." + key)
+	}
+	cfg := &RedisConfig{
+		Mode:             strings.ToLower(get("mode")),
+		MasterName:       get("master-name"),
+		Username:         get("username"),
+		Password:         get("password"),
+		SentinelPassword: get("sentinel-password"),
+		DB:               cast.ToInt(get("db")),
+		PoolSize:         cast.ToInt(get("pool-size")),
+		MinIdleConns:     cast.ToInt(get("min-idle-conns")),
+		DialTimeout:      cast.ToDuration(get("dial-timeout")),
+		ReadTimeout:      cast.ToDuration(get("read-timeout")),
+		WriteTimeout:     cast.ToDuration(get("write-timeout")),
 	}
+	for _, addr := range conf.GetVipperCfg().GetStringSlice(prefix + ".addrs") {
+		for _, a := range strings.Split(addr, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				cfg.Addrs = append(cfg.Addrs, a)
+			}
+		}
+	}
+	if len(cfg.Addrs) == 0 {
+		host, port := get("host"), get("port")
+		if host == "" {
+			host = "127.0.0.1"
+		}
+		if port == "" {
+			port = "6379"
+		}
+		cfg.Addrs = []string{host + ":" + port}
+	}
+	switch cfg.Mode {
+	case "", ModeStandalone, ModeSentinel, ModeCluster:
+	default:
+		return nil, fmt.Errorf("unknown redis mode: %s", cfg.Mode)
+	}
+	tlsCfg, err := loadTLSConfig(get)
+	if err != nil {
+		return nil, err
+	}
+	cfg.TLS = tlsCfg
+	return cfg, nil
+}
 
-	return redisClient
+// mode 未配置时，有 master-name 为哨兵，多个地址为集群
+func (cfg *RedisConfig) mode() string {
+	switch {
+	case cfg.Mode != "":
+		return cfg.Mode
+	case cfg.MasterName != "":
+		return ModeSentinel
+	case len(cfg.Addrs) > 1:
+		return ModeCluster
+	}
+	return ModeStandalone
+}
+
+func loadTLSConfig(get func(key string) string) (*tls.Config, error) {
+	ca, cert, key := get("tls.ca"), get("tls.cert"), get("tls.key")
+	if !cast.ToBool(get("tls.enabled")) && ca == "" && cert == "" {
+		return nil, nil
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         get("tls.server-name"),
+		InsecureSkipVerify: cast.ToBool(get("tls.insecure-skip-verify")),
+	}
+	if ca != "" {
+		pem, err := os.ReadFile(ca)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("invalid redis tls ca: " + ca)
+		}
+	}
+	if cert != "" {
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{pair}
+	}
+	return tlsCfg, nil
+}
+
+// Mode 当前连接的部署模式
+func (rcc *RedisClient) Mode() string {
+	return rcc.mode
 }
 
 func (rcc *RedisClient) Ping(ctx context.Context) (string, error) {
@@ -102,17 +316,46 @@ func (rcc *RedisClient) Exists(key string) (int64, error) {
 }
 
 func (rcc *RedisClient) Set(key string, value interface{}, expiration time.Duration) error {
-	if err := rcc.client.Set(context.Background(), key, value, expiration).Err(); err != nil {
+	value, err := MarshalValue(value)
+	if err != nil {
+		return err
+	}
+	if err = rcc.client.Set(context.Background(), key, value, expiration).Err(); err != nil {
 		lv_log.Error(fmt.Sprintf("Set error: %v", err))
 		return err
 	}
 	return nil
 }
 
+// MarshalValue 与内存缓存一致，字符串、数字等基本类型原样写入，结构体等转为json
+func MarshalValue(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, string, []byte, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, bool, time.Time, time.Duration, encoding.BinaryMarshaler:
+		return value, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// SetNX key不存在时才写入，返回是否写入
+func (rcc *RedisClient) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	value, err := MarshalValue(value)
+	if err != nil {
+		return false, err
+	}
+	return rcc.client.SetNX(context.Background(), key, value, expiration).Result()
+}
+
 func (rcc *RedisClient) Get(key string) (string, error) {
 	data, err := rcc.client.Get(context.Background(), key).Result()
 	if err != nil {
-		lv_log.Error(fmt.Sprintf("Get error: %v", err))
+		if err != redis.Nil { // key不存在是正常情况，不记录错误日志
+			lv_log.Error(fmt.Sprintf("Get error: %v", err))
+		}
 		return "", err
 	}
 	return data, nil
@@ -157,6 +400,18 @@ func (rcc *RedisClient) Execute(script string, keys []string, args ...interface{
 	return rcc.client.Eval(context.TODO(), script, keys, args).Result()
 }
 
+// 按脚本内容缓存 redis.Script，执行时优先使用 EVALSHA
+var scripts sync.Map
+
+// RunScript 执行lua脚本，脚本已加载时使用 EVALSHA 避免重复传输脚本内容
+func (rcc *RedisClient) RunScript(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	s, ok := scripts.Load(script)
+	if !ok {
+		s, _ = scripts.LoadOrStore(script, redis.NewScript(script))
+	}
+	return s.(*redis.Script).Run(ctx, rcc.client, keys, args...)
+}
+
 func (rcc *RedisClient) Close() error {
 	return rcc.client.Close()
 }
@@ -165,100 +420,23 @@ func (rcc *RedisClient) Scan(cursor uint64, match string, count int64) ([]string
 	return rcc.client.Scan(context.TODO(), cursor, match, count).Result()
 }
 
+// GetRedis 单机、哨兵模式下的原生客户端，集群模式下返回nil，此时使用 GetUniversal
 func (rcc *RedisClient) GetRedis() *redis.Client {
-	return rcc.client
+	c, _ := rcc.client.(*redis.Client)
+	return c
 }
 
-func (rcc *RedisClient) CountKeysByPattern(pattern string) (int64, error) {
-	var cursor uint64 = 0
-	var count int64 = 0
-
-	for {
-		var keys []string
-		var err error
-
-		// 调用 SCAN 命令
-		keys, cursor, err = rcc.Scan(cursor, pattern, 0)
-		if err != nil {
-			lv_log.Error(fmt.Sprintf("Scan error: %v", err))
-			return 0, err
-		}
-
-		// 更新计数
-		count += int64(len(keys))
-
-		// 如果游标为 0，表示遍历完成
-		if cursor == 0 {
-			break
-		}
-	}
-
-	return count, nil
+// GetUniversal 原生客户端，单机、哨兵、集群模式下分别为 *redis.Client、*redis.Client、*redis.ClusterClient
+func (rcc *RedisClient) GetUniversal() redis.UniversalClient {
+	return rcc.client
 }
 
-func (rcc *RedisClient) GetKeysPage(pattern string, page int, pageSize int) (keys []string, total int, err error) {
-	// 先获取总数
-	total64, err := rcc.CountKeysByPattern(pattern)
-	if err != nil {
-		return nil, 0, err
-	}
-	total = int(total64)
-
-	if total == 0 {
-		return []string{}, 0, nil
-	}
-
-	start := (page - 1) * pageSize
-	if start < 0 {
-		start = 0
-	}
-	if start >= total {
-		return []string{}, total, nil
-	}
-
-	end := start + pageSize
-	if end > total {
-		end = total
-	}
-	//1. 去掉预分配：改为 var result []string，slice 长度完全由实际数据决定
-	//2. SCAN count 动态调整：pageSize * 2，最少 100。这样：
-	//- 小页码（如 pageSize=10）时用 100，减少往返次数
-	//- 大页码（如 pageSize=500）时用 1000，提高效率
-	//SCAN 的 count 参数只是"建议"值，Redis 可能返回更多或更少的 key。
-	//3. 注意：Redis SCAN 返回的 key 是无序的，需要对结果排序的话可以在外部使用 sort.Strings()
-	// 根据 pageSize 计算 SCAN 的 count 参数
-	scanCount := int64(pageSize * 2)
-	if scanCount < 100 {
-		scanCount = 100
-	}
-
-	// 只扫描当前页需要的数据
-	var result []string
-	var count int
-	var cursor uint64 = 0
-
-	for {
-		var batch []string
-		batch, cursor, err = rcc.Scan(cursor, pattern, scanCount)
-		if err != nil {
-			lv_log.Error(fmt.Sprintf("Scan error: %v", err))
-			return nil, 0, err
-		}
-
-		for _, key := range batch {
-			if count >= start {
-				result = append(result, key)
-			}
-			count++
-			if count >= end {
-				return result, total, nil
-			}
-		}
-
-		if cursor == 0 {
-			break
-		}
-	}
+// Publish 发布消息到指定频道
+func (rcc *RedisClient) Publish(ctx context.Context, channel string, message interface{}) error {
+	return rcc.client.Publish(ctx, channel, message).Err()
+}
 
-	return result, total, nil
+// Subscribe 订阅频道，使用完毕后需调用 PubSub.Close
+func (rcc *RedisClient) Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return rcc.client.Subscribe(ctx, channels...)
 }
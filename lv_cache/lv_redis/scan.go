@@ -0,0 +1,214 @@
+package lv_redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+	"github.com/redis/go-redis/v9"
+)
+
+// scanCount 每次SCAN的建议数量，redis 可能返回更多或更少的key
+const scanCount = 500
+
+// delBatch DelByPattern 每批删除的key数量
+const delBatch = 500
+
+// ScanKeys 使用SCAN遍历匹配的key，不阻塞redis；集群模式下遍历所有主节点
+// fn 返回false时停止遍历，同一个key在rehash期间可能被返回多次
+func (rcc *RedisClient) ScanKeys(ctx context.Context, pattern string, fn func(key string) bool) error {
+	if pattern == "" {
+		return lv_ram.KeyNull
+	}
+	cluster, ok := rcc.client.(*redis.ClusterClient)
+	if !ok {
+		_, err := scanNode(ctx, rcc.client, pattern, fn)
+		return err
+	}
+	var mu sync.Mutex //ForEachMaster 并发执行，fn 串行调用
+	stopped := false
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		_, err := scanNode(ctx, node, pattern, func(key string) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if stopped {
+				return false
+			}
+			stopped = !fn(key)
+			return !stopped
+		})
+		return err
+	})
+	return err
+}
+
+// scanNode 遍历单个节点，返回是否被fn中止
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, fn func(key string) bool) (bool, error) {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return false, err
+		}
+		for _, key := range keys {
+			if !fn(key) {
+				return true, nil
+			}
+		}
+		if next == 0 {
+			return false, nil
+		}
+		cursor = next
+	}
+}
+
+// DelByPattern 分批删除匹配的key，使用UNLINK在后台释放内存，返回删除的数量
+// 逐个key发送命令，集群模式下不会出现跨slot错误
+func (rcc *RedisClient) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	var count int64
+	batch := make([]string, 0, delBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		cmds, err := rcc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range batch {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			count += cmd.(*redis.IntCmd).Val()
+		}
+		batch = batch[:0]
+		return err
+	}
+	var delErr error
+	err := rcc.ScanKeys(ctx, pattern, func(key string) bool {
+		batch = append(batch, key)
+		if len(batch) >= delBatch {
+			delErr = flush()
+		}
+		return delErr == nil
+	})
+	if err == nil {
+		err = delErr
+	}
+	if err == nil {
+		err = flush()
+	}
+	return count, err
+}
+
+// matchKeys 匹配的key去重后按字典序排列，保证分页结果稳定
+func (rcc *RedisClient) matchKeys(ctx context.Context, pattern string) ([]string, error) {
+	seen := make(map[string]struct{})
+	err := rcc.ScanKeys(ctx, pattern, func(key string) bool {
+		seen[key] = struct{}{}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// CountKeysByPattern 支持redis的key匹配规则，边遍历边计数，rehash期间重复返回的key会被多计
+func (rcc *RedisClient) CountKeysByPattern(pattern string) (int64, error) {
+	var count int64
+	err := rcc.ScanKeys(context.Background(), pattern, func(key string) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
+// ScanPage 按SCAN游标分页，cursor 为上一页返回的 next，为空时从头开始；next 为空表示已遍历完
+// 集群模式下游标为 "节点序号:节点游标"，节点按地址排序；count 只是建议数量，与SCAN一致可能多于或少于 count
+func (rcc *RedisClient) ScanPage(ctx context.Context, pattern, cursor string, count int) (keys []string, next string, err error) {
+	if pattern == "" {
+		return nil, "", lv_ram.KeyNull
+	}
+	if count <= 0 {
+		count = 10
+	}
+	cluster, ok := rcc.client.(*redis.ClusterClient)
+	if !ok {
+		var c uint64
+		if cursor != "" {
+			if c, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+				return nil, "", fmt.Errorf("invalid cursor: %s", cursor)
+			}
+		}
+		keys, c, err = rcc.client.Scan(ctx, c, pattern, int64(count)).Result()
+		if err != nil || c == 0 {
+			return keys, "", err
+		}
+		return keys, strconv.FormatUint(c, 10), nil
+	}
+	nodes, err := masters(ctx, cluster)
+	if err != nil {
+		return nil, "", err
+	}
+	idx, c := 0, uint64(0)
+	if cursor != "" {
+		i, nc, found := strings.Cut(cursor, ":")
+		idx, err = strconv.Atoi(i)
+		if err == nil && found {
+			c, err = strconv.ParseUint(nc, 10, 64)
+		}
+		if err != nil || !found || idx < 0 {
+			return nil, "", fmt.Errorf("invalid cursor: %s", cursor)
+		}
+	}
+	for ; idx < len(nodes); idx, c = idx+1, 0 { //当前节点遍历完时继续下一个节点，直到取到key
+		keys, c, err = nodes[idx].Scan(ctx, c, pattern, int64(count)).Result()
+		if err != nil {
+			return nil, "", err
+		}
+		if c != 0 {
+			return keys, strconv.Itoa(idx) + ":" + strconv.FormatUint(c, 10), nil
+		}
+		if len(keys) > 0 {
+			if idx+1 < len(nodes) {
+				return keys, strconv.Itoa(idx+1) + ":0", nil
+			}
+			return keys, "", nil
+		}
+	}
+	return []string{}, "", nil
+}
+
+// masters 集群的主节点，按地址排序，保证分页游标中的节点序号稳定
+func masters(ctx context.Context, cluster *redis.ClusterClient) ([]*redis.Client, error) {
+	var mu sync.Mutex
+	nodes := make([]*redis.Client, 0)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+		nodes = append(nodes, node)
+		return nil
+	})
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Options().Addr < nodes[j].Options().Addr
+	})
+	return nodes, err
+}
+
+// GetKeysPage 按字典序分页，与内存实现的结果一致，total 为匹配的key总数，page 从1开始
+func (rcc *RedisClient) GetKeysPage(pattern string, page int, pageSize int) (keys []string, total int, err error) {
+	all, err := rcc.matchKeys(context.Background(), pattern)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lv_ram.PageKeys(all, page, pageSize), len(all), nil
+}
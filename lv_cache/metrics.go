@@ -0,0 +1,149 @@
+package lv_cache
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStat 某个key前缀的统计，Latency 为累计耗时
+type CacheStat struct {
+	Prefix     string        `json:"prefix"`
+	Calls      int64         `json:"calls"`
+	Hits       int64         `json:"hits"`
+	Misses     int64         `json:"misses"`
+	Errors     int64         `json:"errors"`
+	Latency    time.Duration `json:"latency"`
+	MaxLatency time.Duration `json:"maxLatency"`
+}
+
+// HitRate 命中率，没有读操作时为0
+func (s *CacheStat) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type prefixCounter struct {
+	calls, hits, misses, errors, latency, maxLatency atomic.Int64
+}
+
+// 超出前缀数量上限后统一计入该前缀，避免key设计不规范时统计无限增长
+const otherPrefix = "(other)"
+
+// CacheMetrics 按key前缀统计缓存命中、未命中、错误次数和耗时
+type CacheMetrics struct {
+	// KeyPrefix 由key得到统计用的前缀，默认取第一个 : 之前的部分
+	KeyPrefix   func(key string) string
+	MaxPrefixes int
+
+	mu       sync.RWMutex
+	counters map[string]*prefixCounter
+}
+
+var defaultMetrics = NewCacheMetrics()
+
+// Metrics 全局的缓存统计，GetCacheClient 开启统计时写入该对象
+func Metrics() *CacheMetrics {
+	return defaultMetrics
+}
+
+func NewCacheMetrics() *CacheMetrics {
+	return &CacheMetrics{KeyPrefix: DefaultKeyPrefix, MaxPrefixes: 1000, counters: make(map[string]*prefixCounter)}
+}
+
+// DefaultKeyPrefix 取第一个 : 之前的部分，没有 : 的key统计在 (none) 下
+func DefaultKeyPrefix(key string) string {
+	if i := strings.IndexByte(key, ':'); i > 0 {
+		return key[:i]
+	}
+	return "(none)"
+}
+
+func (m *CacheMetrics) counter(key string) *prefixCounter {
+	prefix := m.KeyPrefix(key)
+	m.mu.RLock()
+	c, ok := m.counters[prefix]
+	m.mu.RUnlock()
+	if ok {
+		return c
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok = m.counters[prefix]; ok {
+		return c
+	}
+	if len(m.counters) >= m.MaxPrefixes {
+		prefix = otherPrefix
+		if c, ok = m.counters[prefix]; ok {
+			return c
+		}
+	}
+	c = new(prefixCounter)
+	m.counters[prefix] = c
+	return c
+}
+
+func (m *CacheMetrics) observe(key string, d time.Duration, err error) *prefixCounter {
+	c := m.counter(key)
+	c.calls.Add(1)
+	c.latency.Add(int64(d))
+	for {
+		old := c.maxLatency.Load()
+		if int64(d) <= old || c.maxLatency.CompareAndSwap(old, int64(d)) {
+			break
+		}
+	}
+	if err != nil && !IsNil(err) {
+		c.errors.Add(1)
+	}
+	return c
+}
+
+// ObserveRead 记录一次读操作，出错时不计入命中或未命中
+func (m *CacheMetrics) ObserveRead(key string, d time.Duration, hit bool, err error) {
+	c := m.observe(key, d, err)
+	if err != nil && !IsNil(err) {
+		return
+	}
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// ObserveWrite 记录一次写操作
+func (m *CacheMetrics) ObserveWrite(key string, d time.Duration, err error) {
+	m.observe(key, d, err)
+}
+
+// Stats 所有前缀的统计，按前缀排序
+func (m *CacheMetrics) Stats() []CacheStat {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := make([]CacheStat, 0, len(m.counters))
+	for prefix, c := range m.counters {
+		stats = append(stats, CacheStat{
+			Prefix:     prefix,
+			Calls:      c.calls.Load(),
+			Hits:       c.hits.Load(),
+			Misses:     c.misses.Load(),
+			Errors:     c.errors.Load(),
+			Latency:    time.Duration(c.latency.Load()),
+			MaxLatency: time.Duration(c.maxLatency.Load()),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Prefix < stats[j].Prefix })
+	return stats
+}
+
+// Reset 清空统计
+func (m *CacheMetrics) Reset() {
+	m.mu.Lock()
+	m.counters = make(map[string]*prefixCounter)
+	m.mu.Unlock()
+}
@@ -0,0 +1,27 @@
+package lv_cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+)
+
+func TestInstrumentedCache(t *testing.T) {
+	m := NewCacheMetrics()
+	c := NewInstrumentedCache(lv_ram.NewRamCacheClient(), m)
+	c.Set("user:1", "a", time.Minute)
+	c.Get("user:1")
+	c.Get("user:2")
+	c.Get("token")
+	if _, ok := unwrapCache(c).(*lv_ram.RamCacheClient); !ok {
+		t.Fatal("unwrap")
+	}
+	stats := m.Stats()
+	if len(stats) != 2 || stats[0].Prefix != "(none)" || stats[1].Prefix != "user" {
+		t.Fatal(stats)
+	}
+	if s := stats[1]; s.Calls != 3 || s.Hits != 1 || s.Misses != 1 || s.HitRate() != 0.5 {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,296 @@
+package lv_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/satori/go.uuid"
+)
+
+// 空值占位，远程缓存不存在的key在本地缓存中记为该值，避免缓存穿透
+const negativeValue = "\x00lv_cache:nil\x00"
+
+// MultiLevelCache 二级缓存，本地内存缓存在前，远程缓存(一般为redis)在后
+// 字符串读写会同时缓存在本地，hash操作只读写远程并清除本地同名key
+// 设置了失效通知总线后，写操作会通知其它实例清除本地缓存
+type MultiLevelCache struct {
+	Local       *lv_ram.RamCacheClient
+	Remote      ICache
+	LocalTTL    time.Duration // 本地缓存过期时间，不超过写入时指定的过期时间，0为不过期
+	RemoteTTL   time.Duration // 写入时未指定过期时间则使用该值，0为不过期
+	NegativeTTL time.Duration // 远程不存在的key在本地缓存的时间，0为不缓存空值
+
+	id          string // 实例标识，忽略自己发出的失效通知
+	bus         InvalidationBus
+	unsubscribe func()
+}
+
+// NewMultiLevelCache 创建二级缓存
+func NewMultiLevelCache(local *lv_ram.RamCacheClient, remote ICache, localTTL, remoteTTL, negativeTTL time.Duration) *MultiLevelCache {
+	return &MultiLevelCache{Local: local, Remote: remote, LocalTTL: localTTL, RemoteTTL: remoteTTL, NegativeTTL: negativeTTL,
+		id: uuid.NewV4().String()}
+}
+
+// NewMultiLevelCacheFromConf 按 application.cache 下的配置创建二级缓存
+func NewMultiLevelCacheFromConf(remote ICache) *MultiLevelCache {
+	return NewMultiLevelCache(lv_ram.NewRamCacheClient(), remote,
+		lv_conf.Config().GetDuration(lv_global.KEY_CACHE_LOCAL_TTL, time.Minute),
+		lv_conf.Config().GetDuration(lv_global.KEY_CACHE_REMOTE_TTL, 0),
+		lv_conf.Config().GetDuration(lv_global.KEY_CACHE_NEGATIVE_TTL, 0))
+}
+
+// SetBus 设置失效通知总线，接收其它实例的通知并清除本地缓存，Close 时一并关闭总线
+func (c *MultiLevelCache) SetBus(bus InvalidationBus) {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+	c.bus = bus
+	c.unsubscribe = bus.Subscribe(func(msg *InvalidateMessage) {
+		if msg.Source != c.id {
+			c.Local.Del(msg.Keys...)
+			for _, pattern := range msg.Patterns {
+				c.Local.DelByPattern(context.Background(), pattern)
+			}
+		}
+	})
+}
+
+func (c *MultiLevelCache) Set(key string, value interface{}, expiration time.Duration) error {
+	if expiration == 0 {
+		expiration = c.RemoteTTL
+	}
+	if err := c.Remote.Set(key, value, expiration); err != nil {
+		return err
+	}
+	c.publish(key)
+	if str, ok := value.(string); ok && str != "" {
+		return c.Local.Set(key, str, c.localTTL(expiration))
+	}
+	return c.Local.Del(key)
+}
+
+// SetNX 只在远程缓存判断key是否存在，写入成功后清除本地及其它实例的本地缓存
+func (c *MultiLevelCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	if expiration == 0 {
+		expiration = c.RemoteTTL
+	}
+	ok, err := c.Remote.SetNX(key, value, expiration)
+	if ok {
+		c.Local.Del(key)
+		c.publish(key)
+	}
+	return ok, err
+}
+
+func (c *MultiLevelCache) Get(key string) (string, error) {
+	if data, err := c.Local.Get(key); err == nil {
+		if data == negativeValue {
+			return "", lv_ram.Nil
+		}
+		return data, nil
+	}
+	data, err := c.Remote.Get(key)
+	if err != nil {
+		if IsNil(err) {
+			if c.NegativeTTL > 0 {
+				c.Local.Set(key, negativeValue, c.NegativeTTL)
+			}
+			return "", lv_ram.Nil
+		}
+		return "", err
+	}
+	if data != "" {
+		c.backfill(key, data)
+	}
+	return data, nil
+}
+
+func (c *MultiLevelCache) Del(keys ...string) error {
+	c.Local.Del(keys...)
+	err := c.Remote.Del(keys...)
+	c.publish(keys...)
+	return err
+}
+
+// Evict 只清除本地缓存，用于其它实例修改数据后的失效通知
+func (c *MultiLevelCache) Evict(keys ...string) {
+	c.Local.Del(keys...)
+}
+
+func (c *MultiLevelCache) HSet(key string, values ...interface{}) error {
+	c.Local.Del(key)
+	err := c.Remote.HSet(key, values...)
+	c.publish(key)
+	return err
+}
+
+func (c *MultiLevelCache) HMSet(key string, mp map[string]any, duration time.Duration) error {
+	c.Local.Del(key)
+	err := c.Remote.HMSet(key, mp, duration)
+	c.publish(key)
+	return err
+}
+
+func (c *MultiLevelCache) HGet(key, field string) (string, error) {
+	return c.Remote.HGet(key, field)
+}
+
+func (c *MultiLevelCache) HDel(key string, fields ...string) error {
+	return c.Remote.HDel(key, fields...)
+}
+
+func (c *MultiLevelCache) HGetAll(key string) (map[string]string, error) {
+	return c.Remote.HGetAll(key)
+}
+
+func (c *MultiLevelCache) Exists(key string) (int64, error) {
+	return c.Remote.Exists(key)
+}
+
+func (c *MultiLevelCache) Close() error {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+	if c.bus != nil {
+		if err := c.bus.Close(); err != nil {
+			lv_log.Error("close invalidation bus error:", err)
+		}
+	}
+	c.Local.Close()
+	return c.Remote.Close()
+}
+
+func (c *MultiLevelCache) Expire(key string, duration time.Duration) error {
+	c.Local.Del(key)
+	err := c.Remote.Expire(key, duration)
+	c.publish(key)
+	return err
+}
+
+func (c *MultiLevelCache) CountKeysByPattern(pattern string) (int64, error) {
+	return c.Remote.CountKeysByPattern(pattern)
+}
+
+func (c *MultiLevelCache) GetKeysPage(pattern string, page int, pageSize int) (keys []string, total int, err error) {
+	return c.Remote.GetKeysPage(pattern, page, pageSize)
+}
+
+// ScanKeys 遍历远程缓存中匹配的key
+func (c *MultiLevelCache) ScanKeys(ctx context.Context, pattern string, fn func(key string) bool) error {
+	return c.Remote.ScanKeys(ctx, pattern, fn)
+}
+
+func (c *MultiLevelCache) ScanPage(ctx context.Context, pattern, cursor string, count int) ([]string, string, error) {
+	return c.Remote.ScanPage(ctx, pattern, cursor, count)
+}
+
+// DelByPattern 删除远程缓存中匹配的key，同时清除本地及其它实例本地缓存中匹配的key
+func (c *MultiLevelCache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	n, err := c.Remote.DelByPattern(ctx, pattern)
+	c.Local.DelByPattern(ctx, pattern)
+	if c.bus != nil {
+		if e := c.bus.Publish(&InvalidateMessage{Source: c.id, Patterns: []string{pattern}}); e != nil {
+			lv_log.Error("publish invalidate message error:", pattern, e)
+		}
+	}
+	return n, err
+}
+
+func (c *MultiLevelCache) publish(keys ...string) {
+	if c.bus == nil || len(keys) == 0 {
+		return
+	}
+	if err := c.bus.Publish(&InvalidateMessage{Source: c.id, Keys: keys}); err != nil {
+		lv_log.Error("publish invalidate message error:", keys, err)
+	}
+}
+
+// backfill 远程读到的值写入本地，本地过期时间不超过远程key的剩余时间
+func (c *MultiLevelCache) backfill(key, data string) {
+	ttl, err := c.Remote.PTTL(key)
+	if err != nil || ttl == -2 || ttl == 0 { //远程已过期或即将过期，不写入本地
+		return
+	}
+	c.Local.Set(key, data, c.localTTL(ttl))
+}
+
+// localTTL 本地缓存不能比远程缓存活得更久
+func (c *MultiLevelCache) localTTL(expiration time.Duration) time.Duration {
+	if expiration > 0 && (c.LocalTTL <= 0 || expiration < c.LocalTTL) {
+		return expiration
+	}
+	return c.LocalTTL
+}
+
+func (c *MultiLevelCache) Incr(key string) (int64, error) {
+	return c.IncrBy(key, 1)
+}
+
+func (c *MultiLevelCache) IncrBy(key string, value int64) (int64, error) {
+	c.Local.Del(key)
+	n, err := c.Remote.IncrBy(key, value)
+	c.publish(key)
+	return n, err
+}
+
+func (c *MultiLevelCache) Decr(key string) (int64, error) {
+	return c.IncrBy(key, -1)
+}
+
+func (c *MultiLevelCache) TTL(key string) (time.Duration, error) {
+	return c.Remote.TTL(key)
+}
+
+func (c *MultiLevelCache) PTTL(key string) (time.Duration, error) {
+	return c.Remote.PTTL(key)
+}
+
+func (c *MultiLevelCache) Type(key string) (string, error) {
+	return c.Remote.Type(key)
+}
+
+// 列表、集合、有序集合只存放在远程缓存
+
+func (c *MultiLevelCache) LPush(key string, values ...interface{}) (int64, error) {
+	return c.Remote.LPush(key, values...)
+}
+
+func (c *MultiLevelCache) RPush(key string, values ...interface{}) (int64, error) {
+	return c.Remote.RPush(key, values...)
+}
+
+func (c *MultiLevelCache) LPop(key string) (string, error) {
+	return c.Remote.LPop(key)
+}
+
+func (c *MultiLevelCache) RPop(key string) (string, error) {
+	return c.Remote.RPop(key)
+}
+
+func (c *MultiLevelCache) LRange(key string, start, stop int64) ([]string, error) {
+	return c.Remote.LRange(key, start, stop)
+}
+
+func (c *MultiLevelCache) SAdd(key string, members ...interface{}) (int64, error) {
+	return c.Remote.SAdd(key, members...)
+}
+
+func (c *MultiLevelCache) SMembers(key string) ([]string, error) {
+	return c.Remote.SMembers(key)
+}
+
+func (c *MultiLevelCache) SIsMember(key string, member interface{}) (bool, error) {
+	return c.Remote.SIsMember(key, member)
+}
+
+func (c *MultiLevelCache) ZAdd(key string, score float64, member string) (int64, error) {
+	return c.Remote.ZAdd(key, score, member)
+}
+
+func (c *MultiLevelCache) ZRangeByScore(key string, min, max string) ([]string, error) {
+	return c.Remote.ZRangeByScore(key, min, max)
+}
@@ -0,0 +1,135 @@
+package lv_cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+)
+
+type cacheUser struct {
+	Id   int64
+	Name string
+}
+
+func TestCodecs(t *testing.T) {
+	c := lv_ram.NewRamCacheClient()
+	for _, codec := range []Codec{JSON, Msgpack, Gob} {
+		if err := SetWith(c, codec, "user", cacheUser{Id: 1, Name: "lv"}, time.Minute); err != nil {
+			t.Fatal(codec.Name(), err)
+		}
+		u, err := GetWith[cacheUser](c, codec, "user")
+		if err != nil || u.Id != 1 || u.Name != "lv" {
+			t.Fatal(codec.Name(), u, err)
+		}
+	}
+	if _, err := GetWith[cacheUser](c, JSON, "none"); !IsNil(err) {
+		t.Fatal(err)
+	}
+}
+
+func TestMultiLevelCache(t *testing.T) {
+	remote := lv_ram.NewRamCacheClient()
+	c := NewMultiLevelCache(lv_ram.NewRamCacheClient(), remote, time.Minute, 0, time.Minute)
+
+	if err := SetWith(c, Msgpack, "user", cacheUser{Id: 2, Name: "lv"}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	remote.Del("user") //本地仍有缓存
+	if u, err := GetWith[cacheUser](c, Msgpack, "user"); err != nil || u.Id != 2 {
+		t.Fatal(u, err)
+	}
+
+	if _, err := c.Get("missing"); !IsNil(err) {
+		t.Fatal(err)
+	}
+	remote.Set("missing", "1", 0) //空值已缓存在本地
+	if _, err := c.Get("missing"); !IsNil(err) {
+		t.Fatal(err)
+	}
+	c.Evict("missing")
+	if v, err := c.Get("missing"); err != nil || v != "1" {
+		t.Fatal(v, err)
+	}
+
+	c.Del("missing")
+	if _, err := remote.Get("missing"); !IsNil(err) {
+		t.Fatal(err)
+	}
+
+	remote.Set("short", "1", 200*time.Millisecond) //回填本地的过期时间不超过远程剩余时间
+	if v, err := c.Get("short"); err != nil || v != "1" {
+		t.Fatal(v, err)
+	}
+	if d, _ := c.Local.PTTL("short"); d <= 0 || d > 200*time.Millisecond {
+		t.Fatal(d)
+	}
+}
+
+func TestMultiLevelInvalidation(t *testing.T) {
+	remote := lv_ram.NewRamCacheClient()
+	bus := NewLocalBus()
+	a := NewMultiLevelCache(lv_ram.NewRamCacheClient(), remote, time.Minute, 0, 0)
+	b := NewMultiLevelCache(lv_ram.NewRamCacheClient(), remote, time.Minute, 0, 0)
+	a.SetBus(bus)
+	b.SetBus(bus)
+
+	a.Set("k", "v1", 0)
+	if v, _ := b.Get("k"); v != "v1" {
+		t.Fatal(v)
+	}
+	a.Set("k", "v2", 0) //b的本地缓存被清除
+	if v, _ := b.Get("k"); v != "v2" {
+		t.Fatal(v)
+	}
+	if v, _ := a.Local.Get("k"); v != "v2" { //忽略自己发出的通知
+		t.Fatal(v)
+	}
+	a.Del("k")
+	if _, err := b.Get("k"); !IsNil(err) {
+		t.Fatal(err)
+	}
+}
+
+type closeBus struct {
+	*LocalBus
+	closed bool
+}
+
+func (b *closeBus) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestBroadcastRamCache(t *testing.T) {
+	bus := &closeBus{LocalBus: NewLocalBus()}
+	a := NewBroadcastRamCache(lv_ram.NewRamCacheClient(), bus)
+	b := NewBroadcastRamCache(lv_ram.NewRamCacheClient(), bus)
+
+	b.Set("k", "old", 0)
+	a.Set("k", "new", 0) //b清除旧值
+	if _, err := b.Get("k"); !IsNil(err) {
+		t.Fatal(err)
+	}
+	if v, _ := a.Get("k"); v != "new" {
+		t.Fatal(v)
+	}
+	b.Set("user:1", "1", 0)
+	a.DelByPattern(context.Background(), "user:*")
+	if _, err := b.Get("user:1"); !IsNil(err) {
+		t.Fatal(err)
+	}
+
+	a.Close()
+	if !bus.closed {
+		t.Fatal("bus not closed")
+	}
+	m := NewMultiLevelCache(lv_ram.NewRamCacheClient(), lv_ram.NewRamCacheClient(), time.Minute, 0, 0)
+	bus.closed = false
+	m.SetBus(bus)
+	m.Close()
+	if !bus.closed {
+		t.Fatal("bus not closed")
+	}
+}
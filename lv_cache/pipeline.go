@@ -0,0 +1,195 @@
+package lv_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_redis"
+	"github.com/redis/go-redis/v9"
+)
+
+// PipeResult 管道中单条命令的结果
+type PipeResult struct {
+	Val interface{}
+	Err error
+}
+
+type pipeOp struct {
+	remote  func(ctx context.Context, p redis.Pipeliner) redis.Cmder
+	local   func(c ICache) (interface{}, error)
+	discard bool // 写命令不返回值，忽略redis的返回
+}
+
+// Pipeline 批量执行缓存命令，redis 下一次网络往返发送，其它实现按顺序执行，结果一致
+//
+//	res, err := lv_cache.NewPipeline(lv_cache.GetCacheClient()).Incr("pv").Expire("pv", time.Hour).Exec()
+type Pipeline struct {
+	cache ICache
+	ops   []pipeOp
+}
+
+func NewPipeline(cache ICache) *Pipeline {
+	return &Pipeline{cache: cache}
+}
+
+func (p *Pipeline) add(remote func(ctx context.Context, p redis.Pipeliner) redis.Cmder, local func(c ICache) (interface{}, error)) *Pipeline {
+	p.ops = append(p.ops, pipeOp{remote: remote, local: local})
+	return p
+}
+
+func (p *Pipeline) write(remote func(ctx context.Context, p redis.Pipeliner) redis.Cmder, local func(c ICache) error) *Pipeline {
+	p.ops = append(p.ops, pipeOp{remote: remote, local: func(c ICache) (interface{}, error) {
+		return nil, local(c)
+	}, discard: true})
+	return p
+}
+
+func (p *Pipeline) Set(key string, value interface{}, expiration time.Duration) *Pipeline {
+	return p.write(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		val, err := lv_redis.MarshalValue(value) //与 RedisClient.Set 一致
+		if err != nil {
+			cmd := redis.NewStatusCmd(ctx, "set", key)
+			cmd.SetErr(err)
+			return cmd
+		}
+		return pipe.Set(ctx, key, val, expiration)
+	}, func(c ICache) error {
+		return c.Set(key, value, expiration)
+	})
+}
+
+func (p *Pipeline) Get(key string) *Pipeline {
+	return p.add(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.Get(ctx, key)
+	}, func(c ICache) (interface{}, error) {
+		return c.Get(key)
+	})
+}
+
+func (p *Pipeline) Del(keys ...string) *Pipeline {
+	return p.write(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.Del(ctx, keys...)
+	}, func(c ICache) error {
+		return c.Del(keys...)
+	})
+}
+
+func (p *Pipeline) Expire(key string, duration time.Duration) *Pipeline {
+	return p.write(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.Expire(ctx, key, duration)
+	}, func(c ICache) error {
+		return c.Expire(key, duration)
+	})
+}
+
+func (p *Pipeline) Incr(key string) *Pipeline {
+	return p.IncrBy(key, 1)
+}
+
+func (p *Pipeline) IncrBy(key string, value int64) *Pipeline {
+	return p.add(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.IncrBy(ctx, key, value)
+	}, func(c ICache) (interface{}, error) {
+		return c.IncrBy(key, value)
+	})
+}
+
+func (p *Pipeline) Decr(key string) *Pipeline {
+	return p.IncrBy(key, -1)
+}
+
+func (p *Pipeline) TTL(key string) *Pipeline {
+	return p.add(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.TTL(ctx, key)
+	}, func(c ICache) (interface{}, error) {
+		return c.TTL(key)
+	})
+}
+
+func (p *Pipeline) HSet(key string, values ...interface{}) *Pipeline {
+	return p.write(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.HSet(ctx, key, values...)
+	}, func(c ICache) error {
+		return c.HSet(key, values...)
+	})
+}
+
+func (p *Pipeline) LPush(key string, values ...interface{}) *Pipeline {
+	return p.add(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.LPush(ctx, key, values...)
+	}, func(c ICache) (interface{}, error) {
+		return c.LPush(key, values...)
+	})
+}
+
+func (p *Pipeline) RPush(key string, values ...interface{}) *Pipeline {
+	return p.add(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.RPush(ctx, key, values...)
+	}, func(c ICache) (interface{}, error) {
+		return c.RPush(key, values...)
+	})
+}
+
+func (p *Pipeline) SAdd(key string, members ...interface{}) *Pipeline {
+	return p.add(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.SAdd(ctx, key, members...)
+	}, func(c ICache) (interface{}, error) {
+		return c.SAdd(key, members...)
+	})
+}
+
+func (p *Pipeline) ZAdd(key string, score float64, member string) *Pipeline {
+	return p.add(func(ctx context.Context, pipe redis.Pipeliner) redis.Cmder {
+		return pipe.ZAdd(ctx, key, redis.Z{Score: score, Member: member})
+	}, func(c ICache) (interface{}, error) {
+		return c.ZAdd(key, score, member)
+	})
+}
+
+// Exec 执行所有命令，按添加顺序返回每条命令的结果
+// 写命令的结果为nil，返回的error为第一个非"不存在"的错误
+func (p *Pipeline) Exec() ([]PipeResult, error) {
+	results := make([]PipeResult, len(p.ops))
+	if rc, ok := unwrapCache(p.cache).(*lv_redis.RedisClient); ok {
+		ctx := context.Background()
+		pipe := rc.GetUniversal().Pipeline()
+		cmds := make([]redis.Cmder, len(p.ops))
+		for i, op := range p.ops {
+			cmds[i] = op.remote(ctx, pipe)
+		}
+		pipe.Exec(ctx) //每条命令的错误在cmd中单独读取
+		for i, cmd := range cmds {
+			results[i] = PipeResult{Err: cmd.Err()}
+			if !p.ops[i].discard {
+				results[i].Val = cmdValue(cmd)
+			}
+		}
+	} else {
+		for i, op := range p.ops {
+			val, err := op.local(p.cache)
+			results[i] = PipeResult{Val: val, Err: err}
+		}
+	}
+	p.ops = nil
+	for _, r := range results {
+		if r.Err != nil && !IsNil(r.Err) {
+			return results, r.Err
+		}
+	}
+	return results, nil
+}
+
+func cmdValue(cmd redis.Cmder) interface{} {
+	if cmd.Err() != nil {
+		return nil
+	}
+	switch c := cmd.(type) {
+	case *redis.IntCmd:
+		return c.Val()
+	case *redis.StringCmd:
+		return c.Val()
+	case *redis.DurationCmd:
+		return c.Val()
+	}
+	return nil
+}
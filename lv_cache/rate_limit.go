@@ -0,0 +1,253 @@
+package lv_cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_redis"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/satori/go.uuid"
+)
+
+// 限流算法
+const (
+	TokenBucket   = "token-bucket"   // 令牌桶，允许一定突发
+	SlidingWindow = "sliding-window" // 滑动窗口，窗口内严格不超过 Limit 次
+)
+
+// RateLimitRule 限流规则，Window 内最多 Limit 次请求
+type RateLimitRule struct {
+	Algorithm string        `mapstructure:"algorithm"`
+	Limit     int           `mapstructure:"limit"`
+	Window    time.Duration `mapstructure:"window"`
+	Burst     int           `mapstructure:"burst"` // 令牌桶容量，默认等于 Limit
+}
+
+// RateLimitResult 限流结果
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // 额度完全恢复还需要的时间
+	RetryAfter time.Duration // 被拒绝时距离下次可用的时间
+}
+
+// RateLimiter 限流计数的存储实现
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, rule RateLimitRule) (*RateLimitResult, error)
+}
+
+var (
+	ramRateLimiter     *RamRateLimiter
+	ramRateLimiterOnce sync.Once
+)
+
+// GetRateLimiter 按缓存类型选择限流实现，使用redis时多实例共享计数
+func GetRateLimiter() RateLimiter {
+	switch c := unwrapCache(GetCacheClient()).(type) {
+	case *lv_redis.RedisClient:
+		return NewRedisRateLimiter(c)
+	case *MultiLevelCache:
+		if rc, ok := c.Remote.(*lv_redis.RedisClient); ok {
+			return NewRedisRateLimiter(rc)
+		}
+	}
+	ramRateLimiterOnce.Do(func() {
+		ramRateLimiter = NewRamRateLimiter()
+	})
+	return ramRateLimiter
+}
+
+// Validate 检查规则，Limit 大于0时 Window 不能小于1毫秒，限流按毫秒计算
+func (r RateLimitRule) Validate() error {
+	if r.Limit < 0 || r.Burst < 0 {
+		return fmt.Errorf("rate limit: limit and burst must not be negative")
+	}
+	if r.Limit > 0 && r.Window < time.Millisecond {
+		return fmt.Errorf("rate limit: window %s must be at least 1ms", r.Window)
+	}
+	if r.Algorithm != "" && r.Algorithm != TokenBucket && r.Algorithm != SlidingWindow {
+		return fmt.Errorf("rate limit: unknown algorithm %s", r.Algorithm)
+	}
+	return nil
+}
+
+func (r RateLimitRule) capacity() int {
+	if r.Algorithm != SlidingWindow && r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// rate 令牌桶每毫秒生成的令牌数
+func (r RateLimitRule) rate() float64 {
+	return float64(r.Limit) / float64(r.Window.Milliseconds())
+}
+
+const rateLimitKeyPrefix = "lv_rate:"
+
+// 令牌桶，使用redis服务器时间，避免多实例时钟不一致
+const tokenBucketScript = `
+if redis.replicate_commands then redis.replicate_commands() end
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local data = redis.call('hmget', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('hset', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('pexpire', KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), reset, retry}`
+
+// 滑动窗口，有序集合中保存窗口内每次请求的时间
+const slidingWindowScript = `
+if redis.replicate_commands then redis.replicate_commands() end
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('zremrangebyscore', KEYS[1], '-inf', now - window)
+local count = redis.call('zcard', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('zadd', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('pexpire', KEYS[1], window)
+local reset = 0
+local oldest = redis.call('zrange', KEYS[1], 0, 0, 'withscores')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, limit - count, reset, retry}`
+
+// RedisRateLimiter 基于lua脚本的原子限流，多实例共享计数
+type RedisRateLimiter struct {
+	client *lv_redis.RedisClient
+}
+
+func NewRedisRateLimiter(client *lv_redis.RedisClient) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client}
+}
+
+func (r *RedisRateLimiter) Allow(ctx context.Context, key string, rule RateLimitRule) (*RateLimitResult, error) {
+	var cmd *redis.Cmd
+	if rule.Algorithm == SlidingWindow {
+		cmd = r.client.RunScript(ctx, slidingWindowScript, []string{rateLimitKeyPrefix + key},
+			rule.Limit, rule.Window.Milliseconds(), uuid.NewV4().String())
+	} else {
+		cmd = r.client.RunScript(ctx, tokenBucketScript, []string{rateLimitKeyPrefix + key},
+			rule.capacity(), rule.rate())
+	}
+	arr, err := cmd.Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimitResult{
+		Allowed:    arr[0] == 1,
+		Limit:      rule.capacity(),
+		Remaining:  int(arr[1]),
+		Reset:      time.Duration(arr[2]) * time.Millisecond,
+		RetryAfter: time.Duration(arr[3]) * time.Millisecond,
+	}, nil
+}
+
+type tokenBucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+// RamRateLimiter 进程内限流，算法与redis实现一致
+type RamRateLimiter struct {
+	mu sync.Mutex
+	c  *gocache.Cache
+}
+
+func NewRamRateLimiter() *RamRateLimiter {
+	return &RamRateLimiter{c: gocache.New(time.Minute, time.Minute)}
+}
+
+func (r *RamRateLimiter) Allow(ctx context.Context, key string, rule RateLimitRule) (*RateLimitResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rule.Algorithm == SlidingWindow {
+		return r.slidingWindow(key, rule), nil
+	}
+	return r.tokenBucket(key, rule), nil
+}
+
+func (r *RamRateLimiter) tokenBucket(key string, rule RateLimitRule) *RateLimitResult {
+	now := time.Now()
+	capacity := float64(rule.capacity())
+	rate := rule.rate()
+	b := &tokenBucket{tokens: capacity, ts: now}
+	if v, ok := r.c.Get(key); ok {
+		b = v.(*tokenBucket)
+	}
+	elapsed := float64(now.Sub(b.ts)) / float64(time.Millisecond)
+	b.tokens = math.Min(capacity, b.tokens+math.Max(0, elapsed)*rate)
+	b.ts = now
+	res := &RateLimitResult{Limit: rule.capacity()}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1-b.tokens)/rate)) * time.Millisecond
+	}
+	res.Remaining = int(math.Floor(b.tokens))
+	res.Reset = time.Duration(math.Ceil((capacity-b.tokens)/rate)) * time.Millisecond
+	r.c.Set(key, b, res.Reset+time.Second)
+	return res
+}
+
+func (r *RamRateLimiter) slidingWindow(key string, rule RateLimitRule) *RateLimitResult {
+	now := time.Now()
+	var hits []time.Time
+	if v, ok := r.c.Get(key); ok {
+		hits = v.([]time.Time)
+	}
+	start := now.Add(-rule.Window)
+	i := 0
+	for i < len(hits) && !hits[i].After(start) {
+		i++
+	}
+	hits = hits[i:]
+	res := &RateLimitResult{Limit: rule.Limit}
+	if len(hits) < rule.Limit {
+		hits = append(hits, now)
+		res.Allowed = true
+	}
+	res.Remaining = rule.Limit - len(hits)
+	if len(hits) > 0 {
+		res.Reset = hits[0].Add(rule.Window).Sub(now)
+	}
+	if !res.Allowed {
+		res.RetryAfter = res.Reset
+	}
+	r.c.Set(key, hits, rule.Window)
+	return res
+}
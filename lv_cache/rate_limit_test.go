@@ -0,0 +1,41 @@
+package lv_cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRamRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRamRateLimiter()
+	for _, algorithm := range []string{TokenBucket, SlidingWindow} {
+		rule := RateLimitRule{Algorithm: algorithm, Limit: 3, Window: 300 * time.Millisecond}
+		for i := 0; i < 3; i++ {
+			res, _ := limiter.Allow(ctx, algorithm, rule)
+			if !res.Allowed || res.Remaining != 2-i {
+				t.Fatal(algorithm, i, res)
+			}
+		}
+		res, _ := limiter.Allow(ctx, algorithm, rule)
+		if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > rule.Window {
+			t.Fatal(algorithm, res)
+		}
+		time.Sleep(res.RetryAfter + 10*time.Millisecond)
+		if res, _ = limiter.Allow(ctx, algorithm, rule); !res.Allowed {
+			t.Fatal(algorithm, res)
+		}
+	}
+}
+
+func TestRateLimitRuleValidate(t *testing.T) {
+	if err := (RateLimitRule{Limit: 10, Window: 500 * time.Microsecond}).Validate(); err == nil {
+		t.Fatal("window below 1ms accepted")
+	}
+	if err := (RateLimitRule{Limit: 10, Window: time.Millisecond, Algorithm: SlidingWindow}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (RateLimitRule{}).Validate(); err != nil { //未启用全局限流
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,45 @@
+package lv_cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+	"github.com/redis/go-redis/v9"
+)
+
+// IsNil 判断是否为缓存不存在的错误，兼容内存缓存与redis
+func IsNil(err error) bool {
+	return errors.Is(err, lv_ram.Nil) || errors.Is(err, redis.Nil)
+}
+
+// GetT 从默认缓存中读取并按默认序列化方式解码
+// 缓存不存在时返回零值和 lv_ram.Nil/redis.Nil，可用 IsNil 判断
+func GetT[T any](key string) (T, error) {
+	return GetWith[T](GetCacheClient(), DefaultCodec(), key)
+}
+
+// SetT 按默认序列化方式编码后写入默认缓存
+func SetT[T any](key string, value T, expiration time.Duration) error {
+	return SetWith(GetCacheClient(), DefaultCodec(), key, value, expiration)
+}
+
+// GetWith 使用指定的缓存和序列化方式读取
+func GetWith[T any](cache ICache, codec Codec, key string) (T, error) {
+	var value T
+	data, err := cache.Get(key)
+	if err != nil {
+		return value, err
+	}
+	err = codec.Unmarshal([]byte(data), &value)
+	return value, err
+}
+
+// SetWith 使用指定的缓存和序列化方式写入
+func SetWith[T any](cache ICache, codec Codec, key string, value T, expiration time.Duration) error {
+	data, err := codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return cache.Set(key, string(data), expiration)
+}
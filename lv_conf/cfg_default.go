@@ -69,19 +69,18 @@ func (e *CfgDefault) GetSessionTimeout(defaultTimeout time.Duration) time.Durati
 	return e.sessionTimeout
 }
 
+// GetDuration 读取时长配置，例如 30s、5m，未配置或格式错误时返回默认值
 func (e *CfgDefault) GetDuration(key string, defaultDuration time.Duration) time.Duration {
-	timeoutStr := e.GetValueStr(lv_global.SESSION_TIMEOUT_KEY)
-	if timeoutStr == "" { // 设置一个长期的过期时间
-		lv_log.Warn("No Duration Configured! default:", defaultDuration)
+	str := e.GetValueStr(key)
+	if str == "" {
+		return defaultDuration
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		lv_log.Error("time.ParseDuration error:", key, err)
 		return defaultDuration
-	} else {
-		timeout, err := time.ParseDuration(timeoutStr)
-		if err != nil {
-			lv_log.Error("time.ParseDuration(timeout) error:", key, err)
-			return defaultDuration
-		}
-		return timeout
 	}
+	return d
 }
 func (e *CfgDefault) GetDatasourceDefault() string {
 	if e.DataSourceDefault == "" {
@@ -89,9 +89,11 @@ func (e *Engine) GetDataSource(name string) *DataSource {
 	return e.dataSources[name]
 }
 
-// GetAllDataSources 获取所有数据源配置
+// GetAllDataSources 获取所有数据源配置，包括创建连接失败的数据源
 func (e *Engine) GetAllDataSources() map[string]*DataSource {
-	// 系统启动后数据源配置不会变更，无需加锁
+	// 数据源可能在使用时才初始化，需要加锁
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	result := make(map[string]*DataSource)
 	for k, v := range e.dataSources {
 		result[k] = v
@@ -99,6 +101,17 @@ func (e *Engine) GetAllDataSources() map[string]*DataSource {
 	return result
 }
 
+// GetAllDB 获取已创建的数据库连接，不会初始化未使用的数据源
+func (e *Engine) GetAllDB() map[string]*gorm.DB {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	result := make(map[string]*gorm.DB, len(e.gormMap))
+	for k, v := range e.gormMap {
+		result[k] = v
+	}
+	return result
+}
+
 // GetDB 根据名称获取数据库连接
 func (e *Engine) GetDB(name string) *gorm.DB {
 	// 快速路径：直接从map中读取，不加锁
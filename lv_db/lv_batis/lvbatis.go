@@ -29,9 +29,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/lostvip-com/lv_framework/lv_conf"
 	"github.com/lostvip-com/lv_framework/lv_log"
 	"github.com/lostvip-com/lv_framework/utils/lv_file"
 	"github.com/lostvip-com/lv_framework/utils/lv_reflect"
+	"github.com/lostvip-com/lv_framework/utils/lv_sql"
 	"github.com/lostvip-com/lv_framework/utils/lv_tpl"
 	"github.com/morrisxyang/xreflect"
 	"github.com/spf13/cast"
@@ -58,6 +60,8 @@ type LvBatis struct {
 	Vars        map[string]map[string]any
 	TplFile     string
 	CurrBaseSql string
+	Driver      string            //数据库类型，用于排序列的转义，为空时取默认数据源的驱动
+	SortColumns map[string]string //参数为map时的排序白名单(对外字段名 -> 列名)，未设置时map参数不能传 orderByColumn
 }
 
 /**
@@ -80,6 +84,10 @@ func NewInstance(relativePath string) *LvBatis {
 }
 
 func (d *LvBatis) GetSql(tagName string, params interface{}) (string, error) {
+	//模板中可能直接引用了 OrderByColumn，先按白名单校验，防止sql注入
+	if _, err := lv_sql.SortOf(params, d.SortColumns); err != nil {
+		return "", err
+	}
 	query, err := d.LookupQuery(tagName)
 	if err != nil || query == "" {
 		panic("tpl文件格式错误!")
@@ -120,7 +128,12 @@ func (d *LvBatis) GetLimitSqlParams(tagName string, params interface{}) (string,
 	if pageSize == nil || pageNum == nil {
 		return "", nil, errors.New("pageSize and pageNum can not be empty! ")
 	}
+	spec, err := lv_sql.SortOf(params, d.SortColumns)
+	if err != nil {
+		return "", nil, err
+	}
 	sql, err := d.GetSql(tagName, sqlParams)
+	sql = lv_sql.WithOrderBy(d.getDriver(), sql, spec)
 	start := cast.ToInt64(pageSize) * (cast.ToInt64(pageNum) - 1)
 	sql = sql + " limit  " + cast.ToString(start) + "," + cast.ToString(pageSize)
 	return sql, sqlParams, err
@@ -160,10 +173,15 @@ func (d *LvBatis) GetLimitSql(tagName string, params interface{}) (string, error
 		}
 		d.CurrBaseSql = sql
 	}
+	spec, err := lv_sql.SortOf(params, d.SortColumns)
+	if err != nil {
+		return "", err
+	}
 	start := cast.ToInt64(pageSize) * (cast.ToInt64(pageNum) - 1)
 	//sql = sql + " limit  " + cast.ToString(start) + "," + cast.ToString(pageSize)
 	// 改为可以兼容mysql和postgresql的分页方式
-	sql := d.CurrBaseSql + " limit  " + cast.ToString(pageSize) + " offset " + cast.ToString(start)
+	sql := lv_sql.WithOrderBy(d.getDriver(), d.CurrBaseSql, spec)
+	sql = sql + " limit  " + cast.ToString(pageSize) + " offset " + cast.ToString(start)
 	return sql, nil
 }
 
@@ -175,15 +193,7 @@ func (d *LvBatis) GetCountSql(tagName string, sqlParams interface{}) (string, er
 		}
 		d.CurrBaseSql = sql
 	}
-	index := strings.Index(d.CurrBaseSql, " order ")
-	noOrderSql := d.CurrBaseSql
-	if index >20  { // select * from t where order by
-		noOrderSql = d.CurrBaseSql[:index]
-	}else{
-		noOrderSql = d.CurrBaseSql
-	}
-	sql := " select count(*)  from (" + noOrderSql + ") t "
-	return sql, nil
+	return lv_sql.GetCountSql(d.CurrBaseSql), nil
 }
 
 func (d *LvBatis) GetPageSql(tagName string, sqlParams any) (string, string, error) {
@@ -274,6 +284,19 @@ func (d *LvBatis) getTplFile() string {
 	return d.TplFile
 }
 
+// AllowSort 设置参数为map时允许排序的字段，key 为对外字段名，value 为列名
+func (d *LvBatis) AllowSort(columns map[string]string) *LvBatis {
+	d.SortColumns = columns
+	return d
+}
+
+func (d *LvBatis) getDriver() string {
+	if d.Driver == "" && lv_conf.Config() != nil {
+		d.Driver = lv_conf.Config().GetDriver(lv_conf.Config().GetDatasourceDefault())
+	}
+	return d.Driver
+}
+
 // Load imports sql Queries from any io.Reader.
 func Load(r io.Reader) (*LvBatis, error) {
 	scanner := &Scanner{}
@@ -0,0 +1,132 @@
+package lv_codegen
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/utils/lv_conv"
+	"github.com/lostvip-com/lv_framework/utils/lv_file"
+)
+
+//go:embed templates/*.tpl
+var defaultTemplates embed.FS
+
+// 生成的文件：模板名 -> 输出路径(相对于输出目录)，%s 为表名
+var outputs = []struct {
+	Tpl  string
+	Path string
+	Go   bool
+}{
+	{"model.go.tpl", "model/%s.go", true},
+	{"dto.go.tpl", "dto/%s_req.go", true},
+	{"mapper.sql.tpl", "resources/mapper/%s.sql", false},
+	{"handler.go.tpl", "handler/%s_handler.go", true},
+}
+
+// Generator 根据表结构生成 model、查询dto、mapper sql 和 gin handler
+type Generator struct {
+	Driver    string // 数据库类型，决定mapper中like的写法
+	OutDir    string // 输出目录，通常为项目根目录
+	Module    string // 输出目录对应的go包路径
+	TplDir    string // 自定义模板目录，存在同名模板时覆盖默认模板
+	Overwrite bool   // 是否覆盖已存在的文件
+}
+
+// TplData 模板数据
+type TplData struct {
+	*Table
+	Driver string
+	Module string
+}
+
+// RoutePath 路由路径 sys_user -> /sys/user
+func (d *TplData) RoutePath() string {
+	return "/" + strings.ReplaceAll(d.Name, "_", "/")
+}
+
+// Permission 权限前缀 sys_user -> sys:user
+func (d *TplData) Permission() string {
+	return strings.ReplaceAll(d.Name, "_", ":")
+}
+
+// VarName 首字母小写的变量名
+func (d *TplData) VarName() string {
+	return lv_conv.ToCamelFirstLower(d.Name)
+}
+
+// HasTime 是否有时间字段，决定是否导入 lv_time
+func (d *TplData) HasTime() bool {
+	for _, c := range d.Columns {
+		if c.Kind() == KindTime {
+			return true
+		}
+	}
+	return false
+}
+
+// Like 按方言生成模糊查询条件
+func (d *TplData) Like(param string) string {
+	if d.Driver == "mysql" {
+		return "concat('%', @" + param + ", '%')"
+	}
+	return "'%' || @" + param + " || '%'"
+}
+
+// Generate 为每张表生成代码，返回生成的文件列表
+func (g *Generator) Generate(tables []*Table) ([]string, error) {
+	files := make([]string, 0)
+	for _, out := range outputs {
+		tpl, err := g.loadTemplate(out.Tpl)
+		if err != nil {
+			return files, err
+		}
+		for _, t := range tables {
+			path := filepath.Join(g.OutDir, fmt.Sprintf(out.Path, t.Name))
+			if !g.Overwrite && lv_file.IsFileExist(path) {
+				lv_log.Info("skip exist file: " + path)
+				continue
+			}
+			buf := new(bytes.Buffer)
+			data := &TplData{Table: t, Driver: g.Driver, Module: g.Module}
+			if err = tpl.Execute(buf, data); err != nil {
+				return files, fmt.Errorf("%s %s: %w", out.Tpl, t.Name, err)
+			}
+			content := buf.Bytes()
+			if out.Go {
+				if content, err = format.Source(content); err != nil {
+					return files, fmt.Errorf("%s %s: %w", out.Tpl, t.Name, err)
+				}
+			}
+			if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return files, err
+			}
+			if err = os.WriteFile(path, content, 0644); err != nil {
+				return files, err
+			}
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// loadTemplate 优先使用自定义目录中的模板，模板使用 [[ ]] 作为分隔符，避免与mapper中的 {{ }} 冲突
+func (g *Generator) loadTemplate(name string) (*template.Template, error) {
+	var content []byte
+	var err error
+	if g.TplDir != "" && lv_file.IsFileExist(filepath.Join(g.TplDir, name)) {
+		content, err = os.ReadFile(filepath.Join(g.TplDir, name))
+	} else {
+		content, err = defaultTemplates.ReadFile("templates/" + name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return template.New(name).Delims("[[", "]]").Parse(string(content))
+}
@@ -0,0 +1,77 @@
+package lv_codegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// sampleTables 覆盖主键、可空、时间、二进制、postgres 特有类型等情况
+func sampleTables() []*Table {
+	return []*Table{{
+		Name:    "sys_user",
+		Comment: "用户",
+		Columns: []*Column{
+			{Name: "user_id", DataType: "bigint unsigned", Comment: "主键", IsPk: true, AutoIncr: true},
+			{Name: "user_name", DataType: "varchar(64)", Comment: "用户名"},
+			{Name: "age", DataType: "int(11)", Nullable: true},
+			{Name: "balance", DataType: "decimal(10,2)"},
+			{Name: "status", DataType: "tinyint(1)"},
+			{Name: "avatar", DataType: "blob", Nullable: true},
+			{Name: "idle", DataType: "interval"},
+			{Name: "create_time", DataType: "datetime", Nullable: true},
+		},
+	}}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	out := t.TempDir()
+	g := &Generator{Driver: "mysql", OutDir: out, Module: "example.com/app", Overwrite: true}
+	files, err := g.Generate(sampleTables())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 {
+		t.Fatal(files)
+	}
+	for _, f := range files {
+		rel, _ := filepath.Rel(out, f)
+		got, _ := os.ReadFile(f)
+		golden := filepath.Join("testdata", rel+".golden")
+		if *update {
+			os.MkdirAll(filepath.Dir(golden), os.ModePerm)
+			if err = os.WriteFile(golden, got, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err, " (run go test -update to create)")
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs from %s:\n%s", rel, golden, got)
+		}
+	}
+}
+
+func TestColumnKind(t *testing.T) {
+	cases := map[string]string{
+		"interval": KindString, "point": KindString, "integer[]": KindString, "character varying(32)": KindString,
+		"int4": KindInt, "bigint(20) unsigned": KindInt, "tinyint(1)": KindBool, "tinyint(4)": KindInt,
+		"double precision": KindFloat, "numeric(10,2)": KindFloat,
+		"timestamp(6) with time zone": KindTime, "datetime": KindTime, "bytea": KindBytes,
+	}
+	for tp, want := range cases {
+		if got := (&Column{DataType: tp}).Kind(); got != want {
+			t.Errorf("%s: got %s want %s", tp, got, want)
+		}
+	}
+	c := &Column{DataType: "datetime", Nullable: true}
+	if c.GoType() != "*lv_time.LvTime" || c.BaseType() != "lv_time.LvTime" {
+		t.Fatal(c.GoType())
+	}
+}
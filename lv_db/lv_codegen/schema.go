@@ -0,0 +1,188 @@
+/*
+ * Copyright 2019 lostvip
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lv_codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lostvip-com/lv_framework/utils/lv_conv"
+	"gorm.io/gorm"
+)
+
+// Table 数据库表结构
+type Table struct {
+	Name    string    // 表名 sys_user
+	Comment string    // 表注释
+	Columns []*Column // 字段，按定义顺序
+}
+
+// Column 数据库字段结构
+type Column struct {
+	Name     string // 列名 create_time
+	DataType string // 数据库类型 varchar(64)
+	Comment  string
+	Nullable bool
+	IsPk     bool
+	AutoIncr bool
+}
+
+// StructName 表名转结构体名称 sys_user -> SysUser
+func (t *Table) StructName() string {
+	return lv_conv.ToCamelFirstUpper(t.Name)
+}
+
+// Pk 单主键，联合主键或无主键时返回nil
+func (t *Table) Pk() *Column {
+	var pk *Column
+	for _, c := range t.Columns {
+		if c.IsPk {
+			if pk != nil {
+				return nil
+			}
+			pk = c
+		}
+	}
+	return pk
+}
+
+// LoadTables 读取数据库中的表结构，names 为空时读取全部表
+func LoadTables(db *gorm.DB, names ...string) ([]*Table, error) {
+	driver := db.Dialector.Name()
+	all, err := listTables(db, driver)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		filter := make(map[string]bool)
+		for _, n := range names {
+			filter[strings.TrimSpace(n)] = true
+		}
+		selected := make([]*Table, 0)
+		for _, t := range all {
+			if filter[t.Name] {
+				selected = append(selected, t)
+				delete(filter, t.Name)
+			}
+		}
+		for n := range filter {
+			return nil, fmt.Errorf("table not found: %s", n)
+		}
+		all = selected
+	}
+	for _, t := range all {
+		if t.Columns, err = listColumns(db, driver, t.Name); err != nil {
+			return nil, err
+		}
+	}
+	return all, nil
+}
+
+func listTables(db *gorm.DB, driver string) ([]*Table, error) {
+	var query string
+	switch driver {
+	case "mysql":
+		query = `select table_name, table_comment from information_schema.tables
+			where table_schema = database() and table_type = 'BASE TABLE' order by table_name`
+	case "postgres":
+		query = `select c.relname, coalesce(obj_description(c.oid, 'pg_class'), '')
+			from pg_class c join pg_namespace n on n.oid = c.relnamespace
+			where c.relkind = 'r' and n.nspname = current_schema() order by c.relname`
+	case "sqlite":
+		query = `select name, '' from sqlite_master where type = 'table' and name not like 'sqlite_%' order by name`
+	default:
+		return nil, fmt.Errorf("%s is not supported", driver)
+	}
+	rows, err := db.Raw(query).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := make([]*Table, 0)
+	for rows.Next() {
+		t := new(Table)
+		if err = rows.Scan(&t.Name, &t.Comment); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+func listColumns(db *gorm.DB, driver, table string) ([]*Column, error) {
+	if driver == "sqlite" {
+		return listSqliteColumns(db, table)
+	}
+	var query string
+	if driver == "mysql" {
+		query = `select column_name, column_type, column_comment, is_nullable = 'YES',
+			column_key = 'PRI', extra like '%auto_increment%'
+			from information_schema.columns
+			where table_schema = database() and table_name = ? order by ordinal_position`
+	} else {
+		query = `select a.attname, format_type(a.atttypid, a.atttypmod),
+			coalesce(col_description(a.attrelid, a.attnum), ''), not a.attnotnull,
+			exists(select 1 from pg_index i where i.indrelid = a.attrelid and i.indisprimary and a.attnum = any(i.indkey)),
+			a.attidentity <> '' or coalesce(pg_get_expr(d.adbin, d.adrelid), '') like 'nextval%'
+			from pg_attribute a left join pg_attrdef d on d.adrelid = a.attrelid and d.adnum = a.attnum
+			where a.attrelid = ?::regclass and a.attnum > 0 and not a.attisdropped order by a.attnum`
+	}
+	rows, err := db.Raw(query, table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make([]*Column, 0)
+	for rows.Next() {
+		c := new(Column)
+		if err = rows.Scan(&c.Name, &c.DataType, &c.Comment, &c.Nullable, &c.IsPk, &c.AutoIncr); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+func listSqliteColumns(db *gorm.DB, table string) ([]*Column, error) {
+	rows, err := db.Raw("select name, type, \"notnull\", pk from pragma_table_info(?)", table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make([]*Column, 0)
+	pkCount := 0
+	for rows.Next() {
+		var notNull, pk int
+		c := new(Column)
+		if err = rows.Scan(&c.Name, &c.DataType, &notNull, &pk); err != nil {
+			return nil, err
+		}
+		c.Nullable = notNull == 0 && pk == 0
+		c.IsPk = pk > 0
+		if c.IsPk {
+			pkCount++
+		}
+		columns = append(columns, c)
+	}
+	// sqlite 中 integer primary key 即 rowid，自增
+	for _, c := range columns {
+		if c.IsPk && pkCount == 1 && strings.EqualFold(c.DataType, "integer") {
+			c.AutoIncr = true
+		}
+	}
+	return columns, rows.Err()
+}
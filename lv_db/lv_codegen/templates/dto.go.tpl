@@ -0,0 +1,19 @@
+// Code generated by lv_gen. You may edit it.
+
+package dto
+
+import "github.com/lostvip-com/lv_framework/web/lv_dto"
+
+// [[ .StructName ]]Req [[ .Comment ]]分页查询条件，lv_sort 为允许客户端排序的列
+type [[ .StructName ]]Req struct {
+	lv_dto.Paging
+[[- range .Columns ]][[ if .Searchable ]]
+[[- if eq .Kind "time" ]]
+	[[ .Field ]] string `form:"[[ .JsonName ]]" json:"[[ .JsonName ]]" lv_sql:"-" lv_sort:"[[ .Name ]]"`
+	Begin[[ .Field ]] string `form:"begin[[ .Field ]]" json:"begin[[ .Field ]]" lv_sql:"type:gte;column:[[ .Name ]];table:[[ $.Name ]]"`
+	End[[ .Field ]] string `form:"end[[ .Field ]]" json:"end[[ .Field ]]" lv_sql:"type:lte;column:[[ .Name ]];table:[[ $.Name ]]"`
+[[- else ]]
+	[[ .Field ]] [[ .SearchType ]] `form:"[[ .JsonName ]]" json:"[[ .JsonName ]]" lv_sql:"type:[[ .SearchOp ]];column:[[ .Name ]];table:[[ $.Name ]]" lv_sort:"[[ .Name ]]"`
+[[- end ]]
+[[- end ]][[ end ]]
+}
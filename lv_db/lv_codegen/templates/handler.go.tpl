@@ -0,0 +1,119 @@
+// Code generated by lv_gen. You may edit it.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_db"
+	"github.com/lostvip-com/lv_framework/lv_db/lv_dao"
+	"github.com/lostvip-com/lv_framework/web/lv_dto"
+	"github.com/lostvip-com/lv_framework/web/lv_router"
+	"[[ .Module ]]/dto"
+	"[[ .Module ]]/model"
+)
+
+// [[ .StructName ]]Handler[[ with .Comment ]] [[ . ]][[ end ]]
+type [[ .StructName ]]Handler struct{}
+
+func init() {
+	h := [[ .StructName ]]Handler{}
+	g := lv_router.New("[[ .RoutePath ]]")
+	g.GET("/list", "[[ .Permission ]]:list", h.List)
+	g.GET("/page", "[[ .Permission ]]:list", h.Page)
+[[- if .Pk ]]
+	g.GET("/get/:id", "[[ .Permission ]]:view", h.Get)
+	g.POST("/add", "[[ .Permission ]]:add", h.Add)
+	g.POST("/edit", "[[ .Permission ]]:edit", h.Edit)
+	g.POST("/remove", "[[ .Permission ]]:remove", h.Remove)
+[[- end ]]
+}
+
+// List 按条件查询全部
+func (h [[ .StructName ]]Handler) List(c *gin.Context) {
+	req := new(dto.[[ .StructName ]]Req)
+	if err := c.ShouldBind(req); err != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(err.Error()))
+		return
+	}
+	rows, err := lv_dao.ListDataByNamedSqlTag[model.[[ .StructName ]]](lv_db.GetOrmDefault(), "[[ .Name ]].sql", "list[[ .StructName ]]", req)
+	if err != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, lv_dto.Resp{Code: lv_dto.SUCCESS, Data: rows, Msg: "success"})
+}
+
+// Page 分页查询
+func (h [[ .StructName ]]Handler) Page(c *gin.Context) {
+	req := new(dto.[[ .StructName ]]Req)
+	if err := c.ShouldBind(req); err != nil {
+		c.JSON(http.StatusOK, lv_dto.FailPage(err.Error()))
+		return
+	}
+	req.GetStartNum()
+	rows, total, err := lv_dao.GetPageByNamedSqlTag[model.[[ .StructName ]]](lv_db.GetOrmDefault(), "[[ .Name ]].sql", "page[[ .StructName ]]", req)
+	if err != nil {
+		c.JSON(http.StatusOK, lv_dto.FailPage(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, lv_dto.SuccessPage[model.[[ .StructName ]]](rows, total))
+}
+[[ with .Pk ]]
+// Get 根据主键查询
+func (h [[ $.StructName ]]Handler) Get(c *gin.Context) {
+	data := new(model.[[ $.StructName ]])
+	err := lv_dao.NewGenericCRUD[model.[[ $.StructName ]]](lv_db.GetOrmDefault()).FindFirst(data, "[[ .Name ]] = ?", c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, lv_dto.Resp{Code: lv_dto.SUCCESS, Data: data, Msg: "success"})
+}
+
+// Add 新增
+func (h [[ $.StructName ]]Handler) Add(c *gin.Context) {
+	data := new(model.[[ $.StructName ]])
+	if err := c.ShouldBindJSON(data); err != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(err.Error()))
+		return
+	}
+	if err := lv_dao.NewGenericCRUD[model.[[ $.StructName ]]](lv_db.GetOrmDefault()).Create(data); err != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, lv_dto.Resp{Code: lv_dto.SUCCESS, Data: data, Msg: "success"})
+}
+
+// Edit 修改
+func (h [[ $.StructName ]]Handler) Edit(c *gin.Context) {
+	data := new(model.[[ $.StructName ]])
+	if err := c.ShouldBindJSON(data); err != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(err.Error()))
+		return
+	}
+	if err := lv_dao.NewGenericCRUD[model.[[ $.StructName ]]](lv_db.GetOrmDefault()).Update(data); err != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, lv_dto.Resp{Code: lv_dto.SUCCESS, Data: data, Msg: "success"})
+}
+
+// Remove 按主键批量删除 {"ids":[1,2]}
+func (h [[ $.StructName ]]Handler) Remove(c *gin.Context) {
+	var req struct {
+		Ids [][[ .BaseType ]] `json:"ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Ids) == 0 {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail("ids can not be empty"))
+		return
+	}
+	tx := lv_db.GetOrmDefault().Where("[[ .Name ]] in ?", req.Ids).Delete(&model.[[ $.StructName ]]{})
+	if tx.Error != nil {
+		c.JSON(http.StatusOK, new(lv_dto.Resp).Fail(tx.Error.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, lv_dto.Resp{Code: lv_dto.SUCCESS, Data: tx.RowsAffected, Msg: "success"})
+}
+[[ end ]]
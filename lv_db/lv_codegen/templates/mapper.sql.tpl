@@ -0,0 +1,39 @@
+-- [[ .Name ]][[ with .Comment ]] [[ . ]][[ end ]]
+-- 查询条件使用 text/template 语法，参数使用 @Name 命名参数，精确匹配的条件为指针，不为 nil 时生效
+-- 排序由分页请求中的 orderByColumn/isAsc 经 lv_sort 白名单校验后自动替换，这里只写默认排序
+
+-- name: list[[ .StructName ]]
+select [[ range $i, $c := .Columns ]][[ if $i ]], [[ end ]]t.[[ $c.Name ]][[ end ]]
+from [[ .Name ]] t
+where 1 = 1
+[[- range .Columns ]][[ if .Searchable ]]
+[[- if eq .Kind "time" ]]
+{{if .Begin[[ .Field ]]}} and t.[[ .Name ]] >= @Begin[[ .Field ]] {{end}}
+{{if .End[[ .Field ]]}} and t.[[ .Name ]] <= @End[[ .Field ]] {{end}}
+[[- else if eq .Kind "string" ]]
+{{if .[[ .Field ]]}} and t.[[ .Name ]] like [[ $.Like .Field ]] {{end}}
+[[- else ]]
+{{if ne .[[ .Field ]] nil}} and t.[[ .Name ]] = @[[ .Field ]] {{end}}
+[[- end ]]
+[[- end ]][[ end ]]
+[[- with .Pk ]]
+order by t.[[ .Name ]] desc
+[[- end ]]
+
+-- name: page[[ .StructName ]]
+select [[ range $i, $c := .Columns ]][[ if $i ]], [[ end ]]t.[[ $c.Name ]][[ end ]]
+from [[ .Name ]] t
+where 1 = 1
+[[- range .Columns ]][[ if .Searchable ]]
+[[- if eq .Kind "time" ]]
+{{if .Begin[[ .Field ]]}} and t.[[ .Name ]] >= @Begin[[ .Field ]] {{end}}
+{{if .End[[ .Field ]]}} and t.[[ .Name ]] <= @End[[ .Field ]] {{end}}
+[[- else if eq .Kind "string" ]]
+{{if .[[ .Field ]]}} and t.[[ .Name ]] like [[ $.Like .Field ]] {{end}}
+[[- else ]]
+{{if ne .[[ .Field ]] nil}} and t.[[ .Name ]] = @[[ .Field ]] {{end}}
+[[- end ]]
+[[- end ]][[ end ]]
+[[- with .Pk ]]
+order by t.[[ .Name ]] desc
+[[- end ]]
@@ -0,0 +1,16 @@
+// Code generated by lv_gen. You may edit it.
+
+package model
+[[ if .HasTime ]]
+import "github.com/lostvip-com/lv_framework/utils/lv_time"
+[[ end ]]
+// [[ .StructName ]][[ with .Comment ]] [[ . ]][[ end ]]
+type [[ .StructName ]] struct {
+[[- range .Columns ]]
+	[[ .Field ]] [[ .GoType ]] `gorm:"[[ .GormTag ]]" json:"[[ .JsonName ]]"` [[ if .Comment ]]// [[ .Comment ]][[ end ]]
+[[- end ]]
+}
+
+func (e *[[ .StructName ]]) TableName() string {
+	return "[[ .Name ]]"
+}
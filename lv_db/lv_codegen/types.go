@@ -0,0 +1,121 @@
+package lv_codegen
+
+import (
+	"strings"
+
+	"github.com/lostvip-com/lv_framework/utils/lv_conv"
+)
+
+// 字段类别，决定生成的go类型以及查询条件
+const (
+	KindString = "string"
+	KindInt    = "int"
+	KindFloat  = "float"
+	KindBool   = "bool"
+	KindTime   = "time"
+	KindBytes  = "bytes"
+)
+
+// 数据库类型名(去掉长度、精度、unsigned 等修饰)对应的字段类别，未列出的类型按字符串处理
+var typeKinds = map[string]string{
+	"bool": KindBool, "boolean": KindBool,
+	"tinyint": KindInt, "smallint": KindInt, "mediumint": KindInt, "int": KindInt, "integer": KindInt, "bigint": KindInt,
+	"int2": KindInt, "int4": KindInt, "int8": KindInt, "smallserial": KindInt, "serial": KindInt, "bigserial": KindInt,
+	"serial2": KindInt, "serial4": KindInt, "serial8": KindInt, "year": KindInt,
+	"decimal": KindFloat, "numeric": KindFloat, "float": KindFloat, "float4": KindFloat, "float8": KindFloat,
+	"double": KindFloat, "double precision": KindFloat, "real": KindFloat,
+	"date": KindTime, "datetime": KindTime, "timestamp": KindTime, "timestamptz": KindTime, "time": KindTime, "timetz": KindTime,
+	"timestamp with time zone": KindTime, "timestamp without time zone": KindTime,
+	"time with time zone": KindTime, "time without time zone": KindTime,
+	"blob": KindBytes, "tinyblob": KindBytes, "mediumblob": KindBytes, "longblob": KindBytes,
+	"binary": KindBytes, "varbinary": KindBytes, "bytea": KindBytes,
+}
+
+// Kind 根据数据库类型推断字段类别，按类型名精确匹配，例如 postgres 的 interval、point 为字符串
+func (c *Column) Kind() string {
+	t := strings.ToLower(strings.TrimSpace(c.DataType))
+	if strings.HasPrefix(t, "tinyint(1)") {
+		return KindBool
+	}
+	if strings.HasSuffix(t, "[]") { //postgres 数组
+		return KindString
+	}
+	if i := strings.Index(t, "("); i >= 0 { //varchar(64)、timestamp(6) with time zone
+		if j := strings.Index(t[i:], ")"); j >= 0 {
+			t = t[:i] + t[i+j+1:]
+		}
+	}
+	t = strings.Join(strings.Fields(strings.NewReplacer(" unsigned", "", " zerofill", "").Replace(t)), " ")
+	if kind, ok := typeKinds[t]; ok {
+		return kind
+	}
+	return KindString
+}
+
+// BaseType 字段的go类型，不区分是否可空，时间统一使用 lv_time.LvTime
+func (c *Column) BaseType() string {
+	switch c.Kind() {
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int64"
+	case KindFloat:
+		return "float64"
+	case KindTime:
+		return "lv_time.LvTime"
+	case KindBytes:
+		return "[]byte"
+	}
+	return "string"
+}
+
+// GoType 字段在model中的go类型，可空的列使用指针，以区分零值和 NULL
+func (c *Column) GoType() string {
+	if c.Nullable && !c.IsPk && c.Kind() != KindBytes {
+		return "*" + c.BaseType()
+	}
+	return c.BaseType()
+}
+
+// Field go字段名 create_time -> CreateTime
+func (c *Column) Field() string {
+	return lv_conv.ToCamelFirstUpper(c.Name)
+}
+
+// JsonName json字段名 create_time -> createTime
+func (c *Column) JsonName() string {
+	return lv_conv.ToCamelFirstLower(c.Name)
+}
+
+// Searchable 是否生成查询条件
+func (c *Column) Searchable() bool {
+	return c.Kind() != KindBytes
+}
+
+// SearchOp lv_sql 查询类型
+func (c *Column) SearchOp() string {
+	if c.Kind() == KindString {
+		return "icontains"
+	}
+	return "exact"
+}
+
+// SearchType 查询条件的go类型，精确匹配的列使用指针，以区分未传和零值，例如 status=0、enabled=false
+func (c *Column) SearchType() string {
+	if c.SearchOp() == "exact" {
+		return "*" + c.BaseType()
+	}
+	return c.BaseType()
+}
+
+// GormTag model上的gorm标签
+func (c *Column) GormTag() string {
+	tag := "column:" + c.Name
+	if c.IsPk {
+		tag += ";primaryKey"
+	}
+	if c.AutoIncr {
+		tag += ";autoIncrement"
+	}
+	return tag
+}
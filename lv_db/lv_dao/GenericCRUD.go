@@ -17,6 +17,7 @@
 package lv_dao
 
 import (
+	"github.com/lostvip-com/lv_framework/utils/lv_sql"
 	"gorm.io/gorm"
 )
 
@@ -59,6 +60,19 @@ func (g *GenericCRUD[T]) FindList(list []T, start int, pageSize int, condition s
 	return result.Error
 }
 
+// FindListSorted 分页查询，req 为分页请求，OrderByColumn/IsAsc 按其类型上 lv_sort 标记的白名单校验后排序，为 nil 时不排序
+func (g *GenericCRUD[T]) FindListSorted(list *[]T, start int, pageSize int, req any, condition string, args ...any) error {
+	sort, err := lv_sql.SortOf(req)
+	if err != nil {
+		return err
+	}
+	tx := g.db.Where(condition, args...)
+	if !sort.IsEmpty() {
+		tx = tx.Order(sort.ToSql(g.db.Dialector.Name()))
+	}
+	return tx.Offset(start).Limit(pageSize).Find(list).Error
+}
+
 // FindFirst 根据ID查找记录
 func (g *GenericCRUD[T]) FindFirst(out *T, condition string, args ...any) error {
 	result := g.db.Where(condition, args...).First(out)
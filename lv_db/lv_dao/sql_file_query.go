@@ -28,6 +28,9 @@ func ListMapByNamedSqlTag(db *gorm.DB, sqlFile string, sqlTag string, req any, i
 	if err != nil {
 		return nil, err
 	}
+	if sql, err = namedsql.OrderBy(db, sql, req); err != nil {
+		return nil, err
+	}
 	return namedsql.ListMap(db, sql, req, isCamel)
 }
 func ListDataByNamedSqlTag[T any](db *gorm.DB, sqlFile string, sqlTag string, req any) ([]T, error) {
@@ -36,6 +39,9 @@ func ListDataByNamedSqlTag[T any](db *gorm.DB, sqlFile string, sqlTag string, re
 	if err != nil {
 		return nil, err
 	}
+	if sql, err = namedsql.OrderBy(db, sql, req); err != nil {
+		return nil, err
+	}
 	return namedsql.ListData[T](db, sql, req)
 }
 func GetSqlByTag(sqlFile string, sqlTag string, req any) (string, error) {
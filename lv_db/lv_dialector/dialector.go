@@ -37,8 +37,9 @@ type DbParamsProvider interface {
 
 // DefaultParamsProvider 默认参数提供者实现
 var DefaultParamsProvider = map[string]DbParamsProvider{
-	"mysql": &MySQLParamsProvider{},
-	"sqlite": &SQLiteParamsProvider{},
+	"mysql":    &MySQLParamsProvider{},
+	"sqlite":   &SQLiteParamsProvider{},
+	"postgres": &PostgreSQLDialector{},
 }
 
 // MySQLParamsProvider MySQL参数提供者
@@ -125,6 +126,10 @@ func GetDialector(dialectorName string) (Dialector, error) {
 			RegisterDialector("sqlite", func() Dialector {
 				return &SQLiteDialector{}
 			})
+		case "postgres":
+			RegisterDialector("postgres", func() Dialector {
+				return &PostgreSQLDialector{}
+			})
 		default:
 			return nil, fmt.Errorf("%s dialector not registered", dialectorName)
 		}
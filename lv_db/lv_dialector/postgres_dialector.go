@@ -24,6 +24,10 @@ func (d *PostgreSQLDialector) GetDefaultParams() map[string]string {
 	}
 }
 
+func (d *PostgreSQLDialector) NewDialector(url string) gorm.Dialector {
+	return postgres.Open(url)
+}
+
 func (d *PostgreSQLDialector) Open(dbCfg *DbConfig) gorm.Dialector {
 	// 合并默认参数和自定义参数
 	params := d.GetDefaultParams()
@@ -19,8 +19,13 @@ import (
 )
 
 func GetPage[T any](db *gorm.DB, sql string, req any) ([]T, int64, error) {
+	//客户端排序参数只允许白名单中的列
+	orderSql, err := OrderBy(db, sql, req)
+	if err != nil {
+		return nil, 0, err
+	}
 	//查询数据
-	limitSql, err := lv_sql.GetLimitSql(sql, req)
+	limitSql, err := lv_sql.GetLimitSql(orderSql, req)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -32,8 +37,12 @@ func GetPage[T any](db *gorm.DB, sql string, req any) ([]T, int64, error) {
 	return rows, count, err
 }
 func GetPageMap(db *gorm.DB, sql string, req any, isCamel bool) ([]map[string]any, int64, error) {
+	orderSql, err := OrderBy(db, sql, req)
+	if err != nil {
+		return nil, 0, err
+	}
 	//查询数据
-	limitSql, err := lv_sql.GetLimitSql(sql, req)
+	limitSql, err := lv_sql.GetLimitSql(orderSql, req)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -44,6 +53,15 @@ func GetPageMap(db *gorm.DB, sql string, req any, isCamel bool) ([]map[string]an
 	count, err := Count(db, lv_sql.GetCountSql(sql), req)
 	return rows, count, err
 }
+// OrderBy 读取请求中的 OrderByColumn/IsAsc，按请求类型的 lv_sort 白名单校验后替换sql的排序
+func OrderBy(db *gorm.DB, sql string, req any) (string, error) {
+	spec, err := lv_sql.SortOf(req)
+	if err != nil {
+		return "", err
+	}
+	return lv_sql.WithOrderBy(db.Dialector.Name(), sql, spec), nil
+}
+
 func Exec(db *gorm.DB, dmlSql string, req map[string]any) (int64, error) {
 	if lv_global.IsDebug {
 		db = db.Debug()
@@ -134,11 +152,8 @@ func Count(db *gorm.DB, countSql string, params any) (int64, error) {
 		db = db.Debug()
 	}
 
-	if !strings.Contains(countSql, "count") {
-		countSql = " select count(*) from (" + countSql + ") t where 1=1  "
-	}
-	if !strings.Contains(countSql, "limit") {
-		countSql = countSql + "   limit 1  "
+	if !lv_sql.IsCountSql(countSql) { //传入的是查询语句，转为统计语句
+		countSql = lv_sql.GetCountSql(countSql)
 	}
 
 	var rows *sql.Rows
@@ -27,6 +27,22 @@ var TraceId = "traceId"
 
 // yaml key
 const (
-	KEY_CACHE_TYPE      = "application.cache-type"
-	SESSION_TIMEOUT_KEY = "application.session.timeout" // 会话超时配置key
+	KEY_CACHE_TYPE           = "application.cache-type"
+	SESSION_TIMEOUT_KEY      = "application.session.timeout"      // 会话超时配置key
+	KEY_SESSION              = "application.session"              // 会话配置，cookie、同时在线数等
+	KEY_CACHE_CODEC          = "application.cache.codec"          // 缓存值序列化方式 json/msgpack/gob
+	KEY_CACHE_LOCAL_TTL      = "application.cache.local-ttl"      // 多级缓存本地缓存过期时间
+	KEY_CACHE_REMOTE_TTL     = "application.cache.remote-ttl"     // 多级缓存远程缓存默认过期时间
+	KEY_CACHE_NEGATIVE_TTL   = "application.cache.negative-ttl"   // 多级缓存空值缓存时间，0为不缓存空值
+	KEY_CACHE_SNAPSHOT       = "application.cache.snapshot"       // 内存缓存快照配置 enabled/path/interval
+	KEY_CACHE_METRICS        = "application.cache.metrics"        // 缓存命中统计开关 enabled
+	KEY_CACHE_ADMIN          = "application.cache.admin"          // 缓存管理接口配置 enabled/path/permission
+	KEY_CACHE_BROADCAST      = "application.cache.broadcast"      // 内存缓存是否通过redis通知其它实例清除同名key
+	KEY_RATE_LIMIT           = "application.rate-limit"           // 限流配置
+	KEY_JWT                  = "application.jwt"                  // jwt 算法、密钥、有效期配置
+	KEY_OPENAPI              = "application.openapi"              // 接口文档配置 enabled/path/ui-path/title/version/cdn
+	KEY_RESPONSE_HTTP_STATUS = "application.response.http-status" // 错误响应是否使用真实的 HTTP 状态码，默认 false 总是 200
+	KEY_HEALTH               = "application.health"               // 健康检查接口配置 enabled/path/timeout/disk-threshold
+	KEY_METRICS              = "application.metrics"              // Prometheus 指标配置 enabled/path
+	KEY_CORS                 = "application.cors"                 // 跨域配置 enabled/allow-origins/groups
 )
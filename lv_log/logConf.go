@@ -48,6 +48,14 @@ func GetLog() ILog {
 	return Log
 }
 
+// Close 关闭日志，日志实现了 io.Closer 时调用其 Close
+func Close() error {
+	if closer, ok := Log.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func Error(args ...interface{}) {
 	if Log != nil {
 		Log.Error(args)
@@ -18,6 +18,7 @@ import (
 type LvLogSlogImpl struct {
 	logger     *slog.Logger
 	baseWriter io.Writer
+	fileLog    *lumberjack.Logger
 }
 
 func InitLog(fileName string) *LvLogSlogImpl {
@@ -34,6 +35,7 @@ func InitLog(fileName string) *LvLogSlogImpl {
 
 	// 3. 创建 lumberjack logger
 	lumberjackLogger := impl.createLumberjack(fileName)
+	impl.fileLog = lumberjackLogger
 
 	// 4. 创建多输出 writer
 	impl.baseWriter = impl.createMultiWriter(lumberjackLogger)
@@ -128,6 +130,14 @@ func (e *LvLogSlogImpl) GetLogWriter() io.Writer {
 	return e.baseWriter
 }
 
+// Close 关闭日志文件，程序退出前调用
+func (e *LvLogSlogImpl) Close() error {
+	if e.fileLog == nil {
+		return nil
+	}
+	return e.fileLog.Close()
+}
+
 func (e *LvLogSlogImpl) Error(args ...interface{}) {
 	e.logger.LogAttrs(context.Background(), slog.LevelError, fmt.Sprint(args...))
 }
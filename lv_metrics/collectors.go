@@ -0,0 +1,98 @@
+package lv_metrics
+
+import (
+	"database/sql"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache"
+	"github.com/lostvip-com/lv_framework/lv_db"
+)
+
+var startTime = time.Now()
+
+// collectRuntime Go 运行时指标，名称与 Prometheus 官方客户端一致
+func collectRuntime(w *Writer) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	w.Header("go_info", "gauge", "Information about the Go environment.")
+	w.Sample("go_info", 1, "version", runtime.Version())
+	w.Header("go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	w.Sample("go_goroutines", float64(runtime.NumGoroutine()))
+	w.Header("go_memstats_alloc_bytes", "gauge", "Number of bytes allocated and still in use.")
+	w.Sample("go_memstats_alloc_bytes", float64(ms.Alloc))
+	w.Header("go_memstats_heap_inuse_bytes", "gauge", "Number of heap bytes that are in use.")
+	w.Sample("go_memstats_heap_inuse_bytes", float64(ms.HeapInuse))
+	w.Header("go_memstats_heap_objects", "gauge", "Number of allocated objects.")
+	w.Sample("go_memstats_heap_objects", float64(ms.HeapObjects))
+	w.Header("go_memstats_sys_bytes", "gauge", "Number of bytes obtained from system.")
+	w.Sample("go_memstats_sys_bytes", float64(ms.Sys))
+	w.Header("go_gc_cycles_total", "counter", "Number of completed GC cycles.")
+	w.Sample("go_gc_cycles_total", float64(ms.NumGC))
+	w.Header("go_gc_pause_seconds_total", "counter", "Total GC pause time in seconds.")
+	w.Sample("go_gc_pause_seconds_total", float64(ms.PauseTotalNs)/1e9)
+	w.Header("process_start_time_seconds", "gauge", "Start time of the process since unix epoch in seconds.")
+	w.Sample("process_start_time_seconds", float64(startTime.Unix()))
+}
+
+// collectDB 已创建的数据源的连接池状态
+func collectDB(w *Writer) {
+	dbs := lv_db.GetInstance().GetAllDB()
+	names := make([]string, 0, len(dbs))
+	stats := make(map[string]sql.DBStats, len(dbs))
+	for name, db := range dbs {
+		if sqlDB, err := db.DB(); err == nil {
+			names = append(names, name)
+			stats[name] = sqlDB.Stats()
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	series := []struct {
+		name, typ, help string
+		value           func(s sql.DBStats) float64
+	}{
+		{"db_pool_open_connections", "gauge", "Number of established connections both in use and idle.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+		{"db_pool_in_use_connections", "gauge", "Number of connections currently in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }},
+		{"db_pool_idle_connections", "gauge", "Number of idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }},
+		{"db_pool_max_open_connections", "gauge", "Maximum number of open connections, 0 is unlimited.", func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }},
+		{"db_pool_wait_total", "counter", "Total number of connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+		{"db_pool_wait_seconds_total", "counter", "Total time blocked waiting for a new connection.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }},
+		{"db_pool_max_idle_closed_total", "counter", "Total number of connections closed due to SetMaxIdleConns.", func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }},
+		{"db_pool_max_lifetime_closed_total", "counter", "Total number of connections closed due to SetConnMaxLifetime.", func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }},
+	}
+	for _, m := range series {
+		w.Header(m.name, m.typ, m.help)
+		for _, name := range names {
+			w.Sample(m.name, m.value(stats[name]), "datasource", name)
+		}
+	}
+}
+
+// collectCache 缓存按 key 前缀的命中统计，需开启 application.cache.metrics.enabled
+func collectCache(w *Writer) {
+	stats := lv_cache.Metrics().Stats()
+	if len(stats) == 0 {
+		return
+	}
+	series := []struct {
+		name, typ, help string
+		value           func(s *lv_cache.CacheStat) float64
+	}{
+		{"cache_requests_total", "counter", "Total number of cache operations by key prefix.", func(s *lv_cache.CacheStat) float64 { return float64(s.Calls) }},
+		{"cache_hits_total", "counter", "Total number of cache read hits by key prefix.", func(s *lv_cache.CacheStat) float64 { return float64(s.Hits) }},
+		{"cache_misses_total", "counter", "Total number of cache read misses by key prefix.", func(s *lv_cache.CacheStat) float64 { return float64(s.Misses) }},
+		{"cache_errors_total", "counter", "Total number of cache errors by key prefix.", func(s *lv_cache.CacheStat) float64 { return float64(s.Errors) }},
+		{"cache_latency_seconds_total", "counter", "Total time spent in cache operations by key prefix.", func(s *lv_cache.CacheStat) float64 { return s.Latency.Seconds() }},
+		{"cache_latency_seconds_max", "gauge", "Maximum cache operation latency by key prefix.", func(s *lv_cache.CacheStat) float64 { return s.MaxLatency.Seconds() }},
+	}
+	for _, m := range series {
+		w.Header(m.name, m.typ, m.help)
+		for i := range stats {
+			w.Sample(m.name, m.value(&stats[i]), "prefix", stats[i].Prefix)
+		}
+	}
+}
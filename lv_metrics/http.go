@@ -0,0 +1,141 @@
+package lv_metrics
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultBuckets 请求耗时直方图的上界，单位秒
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HttpMetrics 按 方法、路由模板、状态码 统计请求数和耗时，未匹配的路由记为 unmatched，避免标签数量无限增长
+type HttpMetrics struct {
+	buckets  []float64
+	mu       sync.Mutex
+	series   map[httpKey]*httpSeries
+	inFlight atomic.Int64
+}
+
+type httpKey struct {
+	method, route, status string
+}
+
+type httpSeries struct {
+	count   uint64
+	sum     float64
+	buckets []uint64 //每个上界的累计次数
+}
+
+var defaultHttp = NewHttpMetrics(DefaultBuckets)
+
+// HTTP 默认的请求指标，Middleware 写入的就是它
+func HTTP() *HttpMetrics {
+	return defaultHttp
+}
+
+func NewHttpMetrics(buckets []float64) *HttpMetrics {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &HttpMetrics{buckets: b, series: make(map[httpKey]*httpSeries)}
+}
+
+// Middleware 统计请求指标，需要在路由匹配后才能取到路由模板，注册在 engine 上紧跟 RecoverError 之后
+func Middleware() gin.HandlerFunc {
+	return defaultHttp.Middleware()
+}
+
+func (m *HttpMetrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.inFlight.Add(1)
+		defer func() {
+			m.inFlight.Add(-1)
+			route := c.FullPath()
+			if route == "" {
+				route = "unmatched"
+			}
+			status := c.Writer.Status()
+			// panic 时还未写入响应，按 500 统计后继续交给 RecoverError 处理
+			r := recover()
+			if r != nil {
+				status = http.StatusInternalServerError
+			}
+			m.Observe(c.Request.Method, route, status, time.Since(start))
+			if r != nil {
+				panic(r)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// Reset 清空已统计的请求
+func (m *HttpMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series = make(map[httpKey]*httpSeries)
+}
+
+// Observe 记录一次请求
+func (m *HttpMetrics) Observe(method, route string, status int, d time.Duration) {
+	key := httpKey{method: method, route: route, status: strconv.Itoa(status)}
+	seconds := d.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.series[key]
+	if s == nil {
+		s = &httpSeries{buckets: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+	s.count++
+	s.sum += seconds
+	for i, upper := range m.buckets {
+		if seconds <= upper {
+			s.buckets[i]++
+		}
+	}
+}
+
+func (m *HttpMetrics) Collect(w *Writer) {
+	m.mu.Lock()
+	keys := make([]httpKey, 0, len(m.series))
+	snapshot := make(map[httpKey]httpSeries, len(m.series))
+	for k, s := range m.series {
+		keys = append(keys, k)
+		snapshot[k] = httpSeries{count: s.count, sum: s.sum, buckets: append([]uint64(nil), s.buckets...)}
+	}
+	m.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	w.Header("http_requests_in_flight", "gauge", "Number of HTTP requests being served.")
+	w.Sample("http_requests_in_flight", float64(m.inFlight.Load()))
+	w.Header("http_requests_total", "counter", "Total number of HTTP requests by method, route and status.")
+	for _, k := range keys {
+		w.Sample("http_requests_total", float64(snapshot[k].count), "method", k.method, "route", k.route, "status", k.status)
+	}
+	w.Header("http_request_duration_seconds", "histogram", "HTTP request latency by method, route and status.")
+	for _, k := range keys {
+		s := snapshot[k]
+		for i, upper := range m.buckets {
+			w.Sample("http_request_duration_seconds_bucket", float64(s.buckets[i]),
+				"method", k.method, "route", k.route, "status", k.status, "le", formatFloat(upper))
+		}
+		w.Sample("http_request_duration_seconds_bucket", float64(s.count), "method", k.method, "route", k.route, "status", k.status, "le", "+Inf")
+		w.Sample("http_request_duration_seconds_sum", s.sum, "method", k.method, "route", k.route, "status", k.status)
+		w.Sample("http_request_duration_seconds_count", float64(s.count), "method", k.method, "route", k.route, "status", k.status)
+	}
+}
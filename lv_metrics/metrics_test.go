@@ -0,0 +1,52 @@
+package lv_metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHttpMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	HTTP().Reset()
+	r := gin.New()
+	r.Use(gin.Recovery(), Middleware())
+	r.GET("/user/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+	r.GET("/metrics", Handler())
+	for _, path := range []string{"/user/1", "/user/2", "/missing", "/panic"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	HTTP().Observe("POST", "/upload", 500, 3*time.Second)
+	Register("app_jobs", CollectorFunc(func(w *Writer) {
+		w.Header("app_jobs", "gauge", "Jobs.")
+		w.Sample("app_jobs", 2, "queue", `a"b`)
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/user/:id",status="200"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_requests_total{method="GET",route="/panic",status="500"} 1`,
+		`http_request_duration_seconds_bucket{method="POST",route="/upload",status="500",le="2.5"} 0`,
+		`http_request_duration_seconds_bucket{method="POST",route="/upload",status="500",le="5"} 1`,
+		`http_request_duration_seconds_bucket{method="POST",route="/upload",status="500",le="+Inf"} 1`,
+		`http_request_duration_seconds_sum{method="POST",route="/upload",status="500"} 3`,
+		`http_requests_in_flight 1`, //当前抓取请求
+		"# TYPE go_goroutines gauge",
+		`app_jobs{queue="a\"b"} 2`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Fatal(want, "\n", body)
+		}
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatal(w.Header())
+	}
+}
@@ -0,0 +1,118 @@
+package lv_metrics
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Collector 每次抓取时将指标写入 Writer
+type Collector interface {
+	Collect(w *Writer)
+}
+
+// CollectorFunc 函数形式的 Collector
+type CollectorFunc func(w *Writer)
+
+func (f CollectorFunc) Collect(w *Writer) {
+	f(w)
+}
+
+type namedCollector struct {
+	name string
+	Collector
+}
+
+var (
+	lock       sync.RWMutex
+	collectors = []namedCollector{
+		{"http", HTTP()},
+		{"runtime", CollectorFunc(collectRuntime)},
+		{"db", CollectorFunc(collectDB)},
+		{"cache", CollectorFunc(collectCache)},
+	}
+)
+
+// Register 注册自定义的指标，同名的会被覆盖，框架已注册 http、runtime、db、cache 四项
+func Register(name string, c Collector) {
+	lock.Lock()
+	defer lock.Unlock()
+	for i := range collectors {
+		if collectors[i].name == name {
+			collectors[i].Collector = c
+			return
+		}
+	}
+	collectors = append(collectors, namedCollector{name, c})
+}
+
+// WriteTo 按 Prometheus 文本格式输出所有指标
+func WriteTo(out io.Writer) error {
+	lock.RLock()
+	list := append([]namedCollector(nil), collectors...)
+	lock.RUnlock()
+	w := &Writer{}
+	for _, c := range list {
+		c.Collect(w)
+	}
+	_, err := out.Write(w.buf.Bytes())
+	return err
+}
+
+// Handler 指标接口，供 Prometheus 抓取
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = WriteTo(c.Writer)
+	}
+}
+
+// Writer Prometheus 文本格式，先调用 Header 再写入同名指标的 Sample
+type Writer struct {
+	buf bytes.Buffer
+}
+
+// Header 写入指标的说明和类型 counter/gauge/histogram
+func (w *Writer) Header(name, typ, help string) {
+	w.buf.WriteString("# HELP " + name + " " + strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(help) + "\n")
+	w.buf.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// Sample 写入一个值，labels 为 名称,值 交替排列
+func (w *Writer) Sample(name string, value float64, labels ...string) {
+	w.buf.WriteString(name)
+	if len(labels) > 1 {
+		w.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.buf.WriteString(labels[i] + `="` + labelEscaper.Replace(labels[i+1]) + `"`)
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteByte(' ')
+	w.buf.WriteString(formatFloat(value))
+	w.buf.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer("\\", `\\`, "\"", `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
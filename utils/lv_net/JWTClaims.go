@@ -18,19 +18,20 @@ package lv_net
 
 import (
 	"errors"
-	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"time"
 )
 
-// BaseModel represents meta data of entity.
+// JWTClaims 旧的令牌信息
+//
+// Deprecated: 密钥写死在代码中，使用 web/lv_auth 代替
 type JWTClaims struct { // token里面添加用户信息，验证token后可能会用到用户信息
 	jwt.StandardClaims
 	Id          int64    `json:"id"`
 	Phone       string   `json:"phone"`
 	Username    string   `json:"username"`
 	Realname    string   `json:"realname"`
-	RoleCodes   []string `json:"permissions"`
+	RoleCodes   []string `json:"roleCodes"`
 	Permissions []string `json:"permissions"`
 }
 
@@ -39,6 +40,7 @@ var (
 	ExpireTime = 3600        // token有效期
 )
 
+// Deprecated: 使用 lv_auth.TokenManager.Generate
 func GetJWTToken(username string, phone string) (signedToken string) {
 	claims := &JWTClaims{
 		Id:          1,
@@ -57,6 +59,7 @@ func GetJWTToken(username string, phone string) (signedToken string) {
 	return signedToken
 }
 
+// Deprecated: 使用 lv_auth.TokenManager.Parse
 func VerifyAction(strToken string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(strToken, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(Secret), nil
@@ -66,18 +69,11 @@ func VerifyAction(strToken string) (*JWTClaims, error) {
 	}
 
 	claims, ok := token.Claims.(*JWTClaims)
-
 	if !ok {
-		fmt.Println("VerifyAction-----not ok--------------》claims:", claims, "---ok:", ok)
 		return nil, errors.New("请重新登录")
 	}
-
-	fmt.Println("VerifyAction------ok-------------》claims:", claims, "---ok:", ok)
-
 	if err := token.Claims.Valid(); err != nil {
-		fmt.Println("token.Claims.Valid()-------------------》err:", err)
 		return nil, errors.New("Token无效，请重新登录")
 	}
-	fmt.Println(" end VerifyAction-------------------》claims:", claims, "---error:", err)
-	return claims, err
+	return claims, nil
 }
@@ -0,0 +1,380 @@
+package lv_sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TokenType sql词法单元类型
+type TokenType int
+
+const (
+	TokenSpace   TokenType = iota // 空白
+	TokenComment                  // -- 或 /* */ 注释
+	TokenWord                     // 关键字或标识符
+	TokenQuoted                   // `ident` 或 "ident"
+	TokenString                   // 'string'
+	TokenNumber                   // 数字
+	TokenParam                    // ? @name :name $1
+	TokenSymbol                   // 其它符号 ( ) , . * 等
+)
+
+// Token sql词法单元，Depth 为所在的括号层级，0 表示顶层
+type Token struct {
+	Type  TokenType
+	Text  string
+	Pos   int
+	Depth int
+}
+
+// Is 判断是否为指定关键字(不区分大小写)
+func (t Token) Is(keyword string) bool {
+	return t.Type == TokenWord && strings.EqualFold(t.Text, keyword)
+}
+
+// Tokenize 轻量级sql词法分析，只区分分页改写所需的词法单元，不做语法校验
+func Tokenize(sql string) []Token {
+	tokens := make([]Token, 0, len(sql)/4)
+	depth := 0
+	i := 0
+	for i < len(sql) {
+		start := i
+		c := sql[i]
+		var tp TokenType
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for i < len(sql) && (sql[i] == ' ' || sql[i] == '\t' || sql[i] == '\n' || sql[i] == '\r') {
+				i++
+			}
+			tp = TokenSpace
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			tp = TokenComment
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i = i + 2 + end + 2
+			}
+			tp = TokenComment
+		case c == '\'':
+			i = scanQuoted(sql, i, '\'', true)
+			tp = TokenString
+		case c == '`' || c == '"':
+			i = scanQuoted(sql, i, c, false)
+			tp = TokenQuoted
+		case isWordStart(c):
+			for i < len(sql) && isWordPart(sql[i]) {
+				i++
+			}
+			tp = TokenWord
+		case c >= '0' && c <= '9':
+			for i < len(sql) && (isWordPart(sql[i]) || sql[i] == '.') {
+				i++
+			}
+			tp = TokenNumber
+		case c == '?':
+			i++
+			tp = TokenParam
+		case (c == '@' || c == ':' || c == '$') && i+1 < len(sql) && isWordPart(sql[i+1]):
+			i++
+			for i < len(sql) && isWordPart(sql[i]) {
+				i++
+			}
+			tp = TokenParam
+		default:
+			i++
+			tp = TokenSymbol
+		}
+		if tp == TokenSymbol && c == ')' && depth > 0 {
+			depth--
+		}
+		tokens = append(tokens, Token{Type: tp, Text: sql[start:i], Pos: start, Depth: depth})
+		if tp == TokenSymbol && c == '(' {
+			depth++
+		}
+	}
+	return tokens
+}
+
+// scanQuoted 扫描引号包裹的内容，两个连续引号视为转义，字符串还支持反斜杠转义
+func scanQuoted(sql string, i int, quote byte, backslash bool) int {
+	i++
+	for i < len(sql) {
+		switch {
+		case backslash && sql[i] == '\\':
+			i += 2
+			continue
+		case sql[i] == quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return len(sql)
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || (c >= '0' && c <= '9')
+}
+
+// sqlClauses 顶层 order by / limit 子句在原sql中的位置，-1 表示不存在
+type sqlClauses struct {
+	orderStart int
+	limitStart int
+	tailEnd    int // 末尾的分号/空白之前的位置
+	tokens     []Token
+}
+
+// 分页子句：limit / offset / fetch 以及 order by 之后可能出现的锁定子句
+var limitKeywords = []string{"limit", "offset", "fetch"}
+var lockKeywords = []string{"for", "lock"}
+
+func parseClauses(sql string) sqlClauses {
+	tokens := Tokenize(sql)
+	cl := sqlClauses{orderStart: -1, limitStart: -1, tailEnd: len(sql), tokens: tokens}
+	for j := len(tokens) - 1; j >= 0; j-- { //去掉末尾的分号、空白、注释
+		t := tokens[j]
+		if t.Type == TokenSpace || t.Type == TokenComment || (t.Type == TokenSymbol && t.Text == ";") {
+			cl.tailEnd = t.Pos
+			continue
+		}
+		break
+	}
+	for j, t := range tokens {
+		if t.Depth != 0 || t.Type != TokenWord || t.Pos >= cl.tailEnd {
+			continue
+		}
+		switch {
+		case t.Is("union") || t.Is("intersect") || t.Is("except"):
+			// 集合运算之前的排序/分页属于左侧子查询，重新查找
+			cl.orderStart, cl.limitStart = -1, -1
+		case t.Is("order") && nextWord(tokens, j).Is("by"):
+			cl.orderStart = t.Pos
+		case isOneOf(t, limitKeywords) && cl.limitStart < 0:
+			cl.limitStart = t.Pos
+		case isOneOf(t, lockKeywords) && cl.limitStart < 0:
+			// for update 等锁定子句不属于排序，当作分页之后的尾部处理
+			cl.limitStart = t.Pos
+		}
+	}
+	if cl.orderStart >= 0 && cl.limitStart >= 0 && cl.limitStart < cl.orderStart {
+		cl.limitStart = -1
+	}
+	return cl
+}
+
+func nextWord(tokens []Token, j int) Token {
+	for k := j + 1; k < len(tokens); k++ {
+		if tokens[k].Type != TokenSpace && tokens[k].Type != TokenComment {
+			return tokens[k]
+		}
+	}
+	return Token{}
+}
+
+func isOneOf(t Token, keywords []string) bool {
+	for _, k := range keywords {
+		if t.Is(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPositionalParam 判断片段中是否有位置参数，去掉后会导致参数个数不匹配
+func hasPositionalParam(tokens []Token, from, to int) bool {
+	for _, t := range tokens {
+		if t.Pos >= from && t.Pos < to && t.Type == TokenParam && (t.Text == "?" || t.Text[0] == '$') {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveOrderBy 移除顶层的 order by 子句，保留 limit 等其余部分；子查询、窗口函数、字符串中的 order by 不受影响
+func RemoveOrderBy(sql string) string {
+	cl := parseClauses(sql)
+	if cl.orderStart < 0 {
+		return sql
+	}
+	end := cl.tailEnd
+	if cl.limitStart >= 0 {
+		end = cl.limitStart
+	}
+	if hasPositionalParam(cl.tokens, cl.orderStart, end) {
+		return sql
+	}
+	head := strings.TrimRight(sql[:cl.orderStart], " \t\r\n")
+	if cl.limitStart < 0 {
+		return head
+	}
+	return head + " " + sql[end:cl.tailEnd]
+}
+
+// RemoveOrderLimit 移除顶层的 order by 和 limit/offset 子句，用于生成count语句
+// 子句中的位置参数(limit ? 等)一并移除，执行时参数需要用 ToCountSqlArgs 同步去掉
+func RemoveOrderLimit(sql string) string {
+	stripped, _ := removeOrderLimit(sql)
+	return stripped
+}
+
+// removeOrderLimit 返回移除后的sql和被移除的位置参数
+func removeOrderLimit(sql string) (string, []Token) {
+	cl := parseClauses(sql)
+	start := cl.orderStart
+	if start < 0 {
+		start = cl.limitStart
+	}
+	if start < 0 {
+		return sql[:cl.tailEnd], nil
+	}
+	return strings.TrimRight(sql[:start], " \t\r\n"), positionalParams(cl.tokens, start, cl.tailEnd)
+}
+
+func positionalParams(tokens []Token, from, to int) []Token {
+	var params []Token
+	for _, t := range tokens {
+		if t.Pos >= from && t.Pos < to && t.Type == TokenParam && (t.Text == "?" || t.Text[0] == '$') {
+			params = append(params, t)
+		}
+	}
+	return params
+}
+
+// ReplaceOrderBy 使用新的 order by 子句替换顶层排序，新子句放在 limit 之前
+func ReplaceOrderBy(sql, orderBy string) string {
+	cl := parseClauses(sql)
+	head, tail := sql[:cl.tailEnd], ""
+	if cl.limitStart >= 0 {
+		head, tail = sql[:cl.limitStart], " "+sql[cl.limitStart:cl.tailEnd]
+	}
+	if cl.orderStart >= 0 {
+		head = sql[:cl.orderStart]
+	}
+	return strings.TrimRight(head, " \t\r\n") + orderBy + tail
+}
+
+// IsCountSql 判断是否已经是 select count(...) from 形式的统计语句
+func IsCountSql(sql string) bool {
+	tokens := significant(Tokenize(sql))
+	if len(tokens) < 5 || !tokens[0].Is("select") || !tokens[1].Is("count") || tokens[2].Text != "(" {
+		return false
+	}
+	for j := 3; j < len(tokens); j++ {
+		if tokens[j].Depth == 0 {
+			if tokens[j].Text != ")" { // count(...) 之后必须紧跟 from 或别名
+				return false
+			}
+			next := j + 1
+			if next < len(tokens) && tokens[next].Is("as") {
+				next += 2
+			} else if next < len(tokens) && tokens[next].Type != TokenWord {
+				return false
+			} else if next < len(tokens) && !tokens[next].Is("from") {
+				next++
+			}
+			return next < len(tokens) && tokens[next].Is("from")
+		}
+	}
+	return false
+}
+
+// 简单查询中，出现以下顶层关键字时不能直接替换select列表
+var unsafeCountKeywords = []string{"order", "distinct", "group", "having", "union", "intersect", "except", "window", "into", "with"}
+
+// ToCountSql 生成统计总数的sql
+// 简单查询直接把select列表改写为 count(*)，其它情况包一层子查询
+// order by/limit 中有位置参数(limit ? 等)时保留原来的子句，保证参数个数不变，需要去掉时使用 ToCountSqlArgs
+func ToCountSql(sql string) string {
+	stripped, removed := removeOrderLimit(sql)
+	if len(removed) > 0 {
+		return wrapCountSql(sql)
+	}
+	return countSql(stripped)
+}
+
+// ToCountSqlArgs 生成统计sql，并去掉被移除的排序/分页子句中位置参数对应的参数
+// ? 参数按出现顺序对应，去掉末尾的参数；$n 参数只能去掉编号最大的几个，否则保留原来的子句
+func ToCountSqlArgs(sql string, args ...any) (string, []any) {
+	stripped, removed := removeOrderLimit(sql)
+	keep := len(args)
+	maxRemain := 0
+	for _, t := range positionalParams(Tokenize(stripped), 0, len(stripped)) {
+		if n, err := strconv.Atoi(strings.TrimPrefix(t.Text, "$")); err == nil && n > maxRemain {
+			maxRemain = n
+		}
+	}
+	for _, t := range removed {
+		if t.Text == "?" {
+			keep--
+			continue
+		}
+		if n, err := strconv.Atoi(t.Text[1:]); err != nil || n <= maxRemain {
+			keep = -1 //去掉后编号不连续
+			break
+		}
+		keep = min(keep, maxRemain)
+	}
+	if keep < 0 {
+		return wrapCountSql(sql), args
+	}
+	return countSql(stripped), args[:keep]
+}
+
+// wrapCountSql 保留原sql的全部子句，包一层子查询统计
+func wrapCountSql(sql string) string {
+	return "select count(*) from (" + sql[:parseClauses(sql).tailEnd] + ") t"
+}
+
+func countSql(stripped string) string {
+	tokens := Tokenize(stripped)
+	sig := significant(tokens)
+	if len(sig) > 0 && sig[0].Is("select") {
+		fromPos := -1
+		safe := true
+		for _, t := range sig[1:] {
+			if t.Depth != 0 {
+				if fromPos < 0 { // select列表中有函数或子查询，可能是聚合，不能改写
+					safe = false
+				}
+				continue
+			}
+			if isOneOf(t, unsafeCountKeywords) || isOneOf(t, limitKeywords) || isOneOf(t, lockKeywords) {
+				safe = false
+				break
+			}
+			if fromPos < 0 {
+				if t.Is("from") {
+					fromPos = t.Pos
+				} else if t.Type == TokenParam || t.Text == "(" {
+					safe = false
+				}
+			}
+		}
+		if safe && fromPos > 0 {
+			return "select count(*) " + stripped[fromPos:]
+		}
+	}
+	return "select count(*) from (" + stripped + ") t"
+}
+
+func significant(tokens []Token) []Token {
+	arr := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Type != TokenSpace && t.Type != TokenComment {
+			arr = append(arr, t)
+		}
+	}
+	return arr
+}
@@ -0,0 +1,79 @@
+package lv_sql
+
+import "testing"
+
+func TestToCountSql(t *testing.T) {
+	cases := []struct{ sql, want string }{
+		{"select * from t", "select count(*) from t"},
+		{"select a, b.c from t where x=@x order by a desc limit 10",
+			"select count(*) from t where x=@x"},
+		{"SELECT id\nFROM t\nORDER BY\n id;", "select count(*) FROM t"},
+		{"select name from t where remark = ' order by x' order by id",
+			"select count(*) from t where remark = ' order by x'"},
+		{"select id, row_number() over (order by id) rn from t order by rn",
+			"select count(*) from (select id, row_number() over (order by id) rn from t) t"},
+		{"select * from (select * from t order by id limit 5) a where a.id > 1",
+			"select count(*) from (select * from t order by id limit 5) a where a.id > 1"},
+		{"select distinct a from t", "select count(*) from (select distinct a from t) t"},
+		{"select max(a) from t", "select count(*) from (select max(a) from t) t"},
+		{"select a from t group by a order by a", "select count(*) from (select a from t group by a) t"},
+		{"select a from x union select a from y order by a",
+			"select count(*) from (select a from x union select a from y) t"},
+		{"select 1", "select count(*) from (select 1) t"},
+		{"select a from t order by ?", "select count(*) from (select a from t order by ?) t"}, //保留位置参数，参数个数不变
+		{"select a from t where b = ? limit ? offset ?", "select count(*) from (select a from t where b = ? limit ? offset ?) t"},
+	}
+	for _, c := range cases {
+		if got := ToCountSql(c.sql); got != c.want {
+			t.Errorf("ToCountSql(%q)\n got: %q\nwant: %q", c.sql, got, c.want)
+		}
+	}
+}
+
+func TestToCountSqlArgs(t *testing.T) {
+	sql, args := ToCountSqlArgs("select * from t where a = ? order by b limit ? offset ?", 1, 10, 20)
+	if sql != "select count(*) from t where a = ?" || len(args) != 1 || args[0] != 1 {
+		t.Fatal(sql, args)
+	}
+	sql, args = ToCountSqlArgs("select * from t where a = $1 limit $2 offset $3", 1, 10, 20)
+	if sql != "select count(*) from t where a = $1" || len(args) != 1 {
+		t.Fatal(sql, args)
+	}
+	sql, args = ToCountSqlArgs("select * from t where a = $2 limit $1", 10, 1)
+	if sql != "select count(*) from (select * from t where a = $2 limit $1) t" || len(args) != 2 {
+		t.Fatal(sql, args)
+	}
+}
+
+func TestReplaceOrderBy(t *testing.T) {
+	cases := []struct{ sql, want string }{
+		{"select * from t", "select * from t order by id"},
+		{"select * from t order by name limit 10", "select * from t order by id limit 10"},
+		{"select * from t /* order by x */ where a in (select b from c order by b)",
+			"select * from t /* order by x */ where a in (select b from c order by b) order by id"},
+		{"select * from t order by name for update", "select * from t order by id for update"},
+	}
+	for _, c := range cases {
+		if got := ReplaceOrderBy(c.sql, " order by id"); got != c.want {
+			t.Errorf("ReplaceOrderBy(%q)\n got: %q\nwant: %q", c.sql, got, c.want)
+		}
+	}
+	if got := RemoveOrderBy("select * from t order by a limit 1"); got != "select * from t limit 1" {
+		t.Error(got)
+	}
+}
+
+func TestIsCountSql(t *testing.T) {
+	yes := []string{"select count(*) from t", "SELECT COUNT(1) AS total FROM t", "select count(id) c from t"}
+	no := []string{"select * from t", "select account from t", "select count(*), a from t", "select counts from t"}
+	for _, s := range yes {
+		if !IsCountSql(s) {
+			t.Errorf("%q should be count sql", s)
+		}
+	}
+	for _, s := range no {
+		if IsCountSql(s) {
+			t.Errorf("%q should not be count sql", s)
+		}
+	}
+}
@@ -17,6 +17,8 @@ const (
 	Mysql = "mysql"
 	// Postgres 数据库标识
 	Postgres = "postgres"
+	// Sqlite 数据库标识
+	Sqlite = "sqlite"
 )
 
 // ResolveSearchQuery 解析
@@ -137,35 +139,7 @@ func GetLimitSql(sql string, params interface{}) (string, error) {
 	return sql, nil
 }
 
+// GetCountSql 生成统计总数的sql，只移除顶层的 order by/limit，简单查询直接改写为 count(*)，子句中有位置参数时保留
 func GetCountSql(sql string) string {
-	// 移除SQL中的ORDER BY子句，因为计数查询不需要排序
-	sqlWithoutOrder := removeOrderBy(sql)
-	return " select count(*)  from (" + sqlWithoutOrder + ") t "
-}
-
-// removeOrderBy 移除SQL中的ORDER BY子句，保留原始大小写
-// removeOrderBy 移除SQL中的ORDER BY子句，保留原始大小写
-func removeOrderBy(sql string) string {
-	// 先转为大写用于查找位置，但不改变原始字符串
-	sqlUpper := strings.ToUpper(sql)
-
-	// 查找ORDER BY的位置（不区分大小写）
-	orderbyIndex := strings.Index(sqlUpper, " ORDER BY ")
-	if orderbyIndex == -1 {
-		return sql // 如果没有找到ORDER BY，直接返回原SQL
-	}
-
-	// 查找ORDER BY之后是否有LIMIT子句
-	limitIndex := strings.Index(sqlUpper[orderbyIndex+len(" ORDER BY "):], " LIMIT ")
-	if limitIndex != -1 {
-		// 如果有LIMIT，只移除ORDER BY部分到LIMIT之前
-		limitStart := orderbyIndex + len(" ORDER BY ") + limitIndex
-		// 找到LIMIT前一个ORDER BY部分的结束位置
-		//orderbyPart := sql[orderbyIndex:limitStart]
-		// 保留LIMIT及其后的内容
-		return sql[:orderbyIndex] + sql[limitStart:]
-	} else {
-		// 如果没有LIMIT，移除ORDER BY及其后所有内容
-		return sql[:orderbyIndex]
-	}
+	return ToCountSql(sql)
 }
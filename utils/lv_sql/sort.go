@@ -0,0 +1,285 @@
+package lv_sql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cast"
+)
+
+// SortTag 排序白名单tag标记，值为数据库列名，例如 `lv_sort:"u.create_time"`
+const SortTag = "lv_sort"
+
+var (
+	ErrSortColumn    = errors.New("order by column is not allowed")
+	ErrSortDirection = errors.New("order by direction must be asc or desc")
+)
+
+// 列名只允许 字母/数字/下划线，可带一级表别名 t.col
+var columnRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// 按DTO类型缓存排序白名单 reflect.Type -> map[公开字段名]列名
+var sortColumnsCache sync.Map
+
+// SortField 单个排序字段
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// SortSpec 多列排序，按顺序生效
+type SortSpec []SortField
+
+// IsEmpty 是否没有任何排序字段
+func (s SortSpec) IsEmpty() bool {
+	return len(s) == 0
+}
+
+// ToSql 生成方言转义后的排序片段，不含 order by 关键字，例如 `u`.`create_time` desc
+func (s SortSpec) ToSql(driver string) string {
+	arr := make([]string, 0, len(s))
+	for _, f := range s {
+		dir := "asc"
+		if f.Desc {
+			dir = "desc"
+		}
+		arr = append(arr, QuoteColumn(driver, f.Column)+" "+dir)
+	}
+	return strings.Join(arr, ", ")
+}
+
+// OrderBy 生成完整的 order by 子句，无排序字段时返回空串
+func (s SortSpec) OrderBy(driver string) string {
+	if s.IsEmpty() {
+		return ""
+	}
+	return " order by " + s.ToSql(driver)
+}
+
+// QuoteColumn 按数据库方言转义列名，postgres 使用双引号，mysql/sqlite 使用反引号
+func QuoteColumn(driver, column string) string {
+	quote := "`"
+	if driver == Postgres {
+		quote = `"`
+	}
+	parts := strings.Split(column, ".")
+	for i, p := range parts {
+		parts[i] = quote + p + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// SortColumns 解析DTO上 lv_sort 标记的排序白名单，key 为对外字段名(json/form/字段名)，value 为列名
+func SortColumns(dto any) (map[string]string, error) {
+	t := reflect.TypeOf(dto)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return map[string]string{}, nil
+	}
+	if v, ok := sortColumnsCache.Load(t); ok {
+		return v.(map[string]string), nil
+	}
+	mp := make(map[string]string)
+	if err := collectSortColumns(t, mp); err != nil {
+		return nil, err
+	}
+	sortColumnsCache.Store(t, mp)
+	return mp, nil
+}
+
+func collectSortColumns(t reflect.Type, mp map[string]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		column, ok := field.Tag.Lookup(SortTag)
+		if !ok {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if field.Anonymous && ft.Kind() == reflect.Struct { //嵌入的结构体，递归解析
+				if err := collectSortColumns(ft, mp); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if column == "-" {
+			continue
+		}
+		if column == "" {
+			column = ToSnake(field.Name)
+		}
+		if !columnRegexp.MatchString(column) {
+			return fmt.Errorf("%s.%s lv_sort column is invalid: %s", t.Name(), field.Name, column)
+		}
+		mp[field.Name] = column
+		for _, key := range []string{"json", "form"} {
+			name := strings.Split(field.Tag.Get(key), ",")[0]
+			if name != "" && name != "-" {
+				mp[name] = column
+			}
+		}
+	}
+	return nil
+}
+
+// ParseSort 按DTO的白名单解析客户端传来的排序参数
+// orderByColumn 支持多列：userName,createTime 或 userName asc,createTime desc
+// isAsc 支持 asc/desc/ascending/descending，多列时逗号分隔，数量不足时沿用最后一个
+func ParseSort(dto any, orderByColumn, isAsc string) (SortSpec, error) {
+	allowed, err := SortColumns(dto)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSortAllowed(allowed, orderByColumn, isAsc)
+}
+
+// ParseSortAllowed 按给定的白名单(对外字段名 -> 列名)解析排序参数
+func ParseSortAllowed(allowed map[string]string, orderByColumn, isAsc string) (SortSpec, error) {
+	orderByColumn = strings.TrimSpace(orderByColumn)
+	if orderByColumn == "" {
+		return SortSpec{}, nil
+	}
+	dirs := make([]string, 0)
+	for _, d := range strings.Split(isAsc, ",") {
+		dirs = append(dirs, strings.TrimSpace(d))
+	}
+	spec := make(SortSpec, 0)
+	for i, item := range strings.Split(orderByColumn, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, ErrSortColumn
+		}
+		column, ok := allowed[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrSortColumn, fields[0])
+		}
+		dir := ""
+		if len(fields) == 2 {
+			dir = fields[1]
+		} else if i < len(dirs) {
+			dir = dirs[i]
+		} else {
+			dir = dirs[len(dirs)-1]
+		}
+		desc, err := parseDirection(dir)
+		if err != nil {
+			return nil, err
+		}
+		spec = append(spec, SortField{Column: column, Desc: desc})
+	}
+	return spec, nil
+}
+
+// SortOf 从分页请求中读取 OrderByColumn/IsAsc 并按请求类型的白名单校验
+// 请求为map时没有tag可用，只接受 allowed 中声明的字段，没有白名单时传了 orderByColumn 直接报错
+func SortOf(req any, allowed ...map[string]string) (SortSpec, error) {
+	orderByColumn, isAsc := readSortParams(req)
+	if orderByColumn == "" {
+		return SortSpec{}, nil
+	}
+	whiteList, err := SortColumns(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, mp := range allowed {
+		for k, v := range mp {
+			whiteList = mergeColumn(whiteList, k, v)
+		}
+	}
+	return ParseSortAllowed(whiteList, orderByColumn, isAsc)
+}
+
+// WithOrderBy 使用排序规则替换sql中原有的 order by，排序为空时原样返回
+func WithOrderBy(driver, sql string, spec SortSpec) string {
+	if spec.IsEmpty() {
+		return sql
+	}
+	return ReplaceOrderBy(sql, spec.OrderBy(driver))
+}
+
+func mergeColumn(mp map[string]string, k, v string) map[string]string {
+	cp := make(map[string]string, len(mp)+1)
+	for key, val := range mp {
+		cp[key] = val
+	}
+	if columnRegexp.MatchString(v) {
+		cp[k] = v
+	}
+	return cp
+}
+
+func readSortParams(req any) (string, string) {
+	if req == nil {
+		return "", ""
+	}
+	if mp, ok := req.(map[string]any); ok {
+		col := mp["orderByColumn"]
+		if col == nil {
+			col = mp["OrderByColumn"]
+		}
+		asc := mp["isAsc"]
+		if asc == nil {
+			asc = mp["IsAsc"]
+		}
+		return cast.ToString(col), cast.ToString(asc)
+	}
+	v := reflect.ValueOf(req)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", ""
+	}
+	var col, asc string
+	if f := v.FieldByName("OrderByColumn"); f.IsValid() && f.Kind() == reflect.String {
+		col = f.String()
+	}
+	if f := v.FieldByName("IsAsc"); f.IsValid() && f.Kind() == reflect.String {
+		asc = f.String()
+	}
+	return col, asc
+}
+
+func parseDirection(dir string) (bool, error) {
+	switch strings.ToLower(dir) {
+	case "", "asc", "ascending":
+		return false, nil
+	case "desc", "descending":
+		return true, nil
+	}
+	return false, fmt.Errorf("%w: %s", ErrSortDirection, dir)
+}
+
+// ToSnake 驼峰转下划线 CreateTime -> create_time，连续大写视为一个词 UserID -> user_id，HTTPServer -> http_server
+func ToSnake(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if isUpper(r) {
+			if i > 0 && (!isUpper(runes[i-1]) || (i+1 < len(runes) && isLower(runes[i+1]))) && runes[i-1] != '_' {
+				sb.WriteByte('_')
+			}
+			r = r + ('a' - 'A')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func isUpper(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+func isLower(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
@@ -0,0 +1,90 @@
+package lv_sql
+
+import (
+	"errors"
+	"testing"
+)
+
+type sortPaging struct {
+	OrderByColumn string `json:"orderByColumn"`
+	IsAsc         string `json:"isAsc"`
+}
+
+type userSortReq struct {
+	sortPaging
+	UserName   string `json:"userName" lv_sort:"u.user_name"`
+	CreateTime string `form:"createTime" lv_sort:""`
+	Password   string `json:"password"`
+}
+
+func TestParseSort(t *testing.T) {
+	spec, err := ParseSort(userSortReq{}, "userName,createTime", "asc,descending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := spec.OrderBy(Mysql); got != " order by `u`.`user_name` asc, `create_time` desc" {
+		t.Fatal(got)
+	}
+	if got := spec.ToSql(Postgres); got != `"u"."user_name" asc, "create_time" desc` {
+		t.Fatal(got)
+	}
+
+	spec, err = ParseSort(&userSortReq{}, "createTime desc, userName", "")
+	if err != nil || spec.ToSql(Sqlite) != "`create_time` desc, `u`.`user_name` asc" {
+		t.Fatal(spec, err)
+	}
+}
+
+func TestParseSortReject(t *testing.T) {
+	cases := []struct{ col, asc string }{
+		{"password", "asc"},
+		{"userName;drop table t", "asc"},
+		{"u.user_name", "asc"},
+		{"userName", "asc;--"},
+		{"userName desc desc", ""},
+	}
+	for _, c := range cases {
+		if _, err := ParseSort(userSortReq{}, c.col, c.asc); err == nil {
+			t.Fatalf("%q %q should be rejected", c.col, c.asc)
+		}
+	}
+	_, err := ParseSort(userSortReq{}, "password", "")
+	if !errors.Is(err, ErrSortColumn) {
+		t.Fatal(err)
+	}
+}
+
+func TestSortOf(t *testing.T) {
+	req := &userSortReq{sortPaging: sortPaging{OrderByColumn: "userName", IsAsc: "desc"}}
+	spec, err := SortOf(req)
+	if err != nil || spec.ToSql(Mysql) != "`u`.`user_name` desc" {
+		t.Fatal(spec, err)
+	}
+	mp := map[string]any{"orderByColumn": "name"}
+	if _, err = SortOf(mp); !errors.Is(err, ErrSortColumn) { //没有白名单时拒绝，防止模板直接引用 orderByColumn
+		t.Fatal(err)
+	}
+	spec, err = SortOf(mp, map[string]string{"name": "t.name"})
+	if err != nil || spec.ToSql(Mysql) != "`t`.`name` asc" {
+		t.Fatal(spec, err)
+	}
+	sql := WithOrderBy(Mysql, "select * from t order by id", spec)
+	if sql != "select * from t order by `t`.`name` asc" {
+		t.Fatal(sql)
+	}
+}
+
+type badSortReq struct {
+	Name string `lv_sort:"name;drop table t"`
+}
+
+func TestSortColumnsAndSnake(t *testing.T) {
+	if _, err := ParseSort(badSortReq{}, "Name", ""); err == nil {
+		t.Fatal("invalid lv_sort column accepted")
+	}
+	for in, want := range map[string]string{"ID": "id", "UserID": "user_id", "CreateTime": "create_time", "HTTPServer": "http_server", "user_name": "user_name"} {
+		if got := ToSnake(in); got != want {
+			t.Fatal(in, got)
+		}
+	}
+}
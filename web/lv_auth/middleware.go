@@ -0,0 +1,66 @@
+package lv_auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/web/lv_dto"
+)
+
+// 保存在 gin.Context 中的令牌信息
+const claimsKey = "lv_auth_claims"
+
+type claimsContextKey struct{}
+
+// NewContext 将令牌信息保存到 context，service 层可以通过 FromContext 读取当前用户
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// FromContext 读取 context 中的令牌信息
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+// GetClaims 当前请求的令牌信息，未经过 Middleware 校验时返回nil
+func GetClaims(c *gin.Context) *Claims {
+	if v, ok := c.Get(claimsKey); ok {
+		return v.(*Claims)
+	}
+	return nil
+}
+
+// GetToken 读取请求头 Authorization: Bearer xxx 中的令牌
+func GetToken(c *gin.Context) string {
+	auth := strings.TrimSpace(c.GetHeader("Authorization"))
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// Middleware 使用 application.jwt 配置校验令牌，配置错误时panic
+func Middleware() gin.HandlerFunc {
+	m, err := Default()
+	if err != nil {
+		panic("jwt 配置错误: " + err.Error())
+	}
+	return m.Middleware()
+}
+
+// Middleware 校验访问令牌，失败时返回401，成功后令牌信息保存在 gin.Context 和 request context 中
+func (m *TokenManager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := m.Parse(GetToken(c))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &lv_dto.CommonRes{Code: http.StatusUnauthorized, Msg: err.Error()})
+			return
+		}
+		c.Set(claimsKey, claims)
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), claims))
+		c.Next()
+	}
+}
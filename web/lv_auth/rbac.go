@@ -0,0 +1,107 @@
+package lv_auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/web/lv_dto"
+	"github.com/lostvip-com/lv_framework/web/lv_router"
+)
+
+// PermissionProvider 查询当前用户拥有的权限，用户未登录时返回 ErrTokenMissing
+type PermissionProvider interface {
+	Permissions(c *gin.Context) ([]string, error)
+}
+
+// PermissionProviderFunc 函数形式的 PermissionProvider，例如从数据库或会话中读取
+type PermissionProviderFunc func(c *gin.Context) ([]string, error)
+
+func (f PermissionProviderFunc) Permissions(c *gin.Context) ([]string, error) {
+	return f(c)
+}
+
+// ClaimsPermissions 使用令牌中的权限，需要先经过 Middleware 校验令牌
+var ClaimsPermissions = PermissionProviderFunc(func(c *gin.Context) ([]string, error) {
+	claims := GetClaims(c)
+	if claims == nil {
+		return nil, ErrTokenMissing
+	}
+	return claims.Permissions, nil
+})
+
+// RBAC 使用令牌中的权限校验路由权限
+func RBAC() gin.HandlerFunc {
+	return RBACWith(ClaimsPermissions)
+}
+
+// RBACWith 按 lv_router 注册路由时的权限字符串校验当前用户的权限
+// 路由模板取自 gin 的 FullPath，带参数的路由同样有效；未设置权限字符串的路由不校验
+func RBACWith(provider PermissionProvider) gin.HandlerFunc {
+	contextPath := strings.TrimSuffix(lv_conf.Config().GetContextPath(), "/")
+	return func(c *gin.Context) {
+		fullPath := c.FullPath()
+		if fullPath == "" { //未匹配到路由
+			c.Next()
+			return
+		}
+		required := lv_router.FindRoutePermission(c.Request.Method, strings.TrimPrefix(fullPath, contextPath))
+		if required == "" {
+			c.Next()
+			return
+		}
+		perms, err := provider.Permissions(c)
+		if err != nil {
+			if errors.Is(err, ErrTokenMissing) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, &lv_dto.CommonRes{Code: http.StatusUnauthorized, Msg: err.Error()})
+				return
+			}
+			lv_log.Error("load permissions error:", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &lv_dto.CommonRes{Code: lv_dto.ERROR, Msg: "获取权限失败"})
+			return
+		}
+		if !HasPermission(perms, required) {
+			c.AbortWithStatusJSON(http.StatusForbidden, &lv_dto.CommonRes{Code: lv_dto.UNAUTHORIZED, Msg: "没有权限: " + required})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasPermission 拥有的权限中是否有一个能匹配 required
+func HasPermission(granted []string, required string) bool {
+	for _, p := range granted {
+		if MatchPermission(p, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPermission 按 : 分段匹配，* 匹配任意一段，最后一段为 * 时匹配剩余所有段
+// 例如 system:user:* 匹配 system:user:view，*:*:* 和 * 匹配所有权限
+func MatchPermission(granted, required string) bool {
+	if granted == required || granted == "*" {
+		return true
+	}
+	g := strings.Split(granted, ":")
+	r := strings.Split(required, ":")
+	for i, seg := range g {
+		if i >= len(r) {
+			return false
+		}
+		if seg == "*" {
+			if i == len(g)-1 {
+				return true
+			}
+			continue
+		}
+		if seg != r[i] {
+			return false
+		}
+	}
+	return len(g) == len(r)
+}
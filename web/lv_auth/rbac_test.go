@@ -0,0 +1,72 @@
+package lv_auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/web/lv_router"
+)
+
+func TestRBAC(t *testing.T) {
+	lv_conf.RegisterCfg(new(lv_conf.CfgDefault))
+	groups := lv_router.GroupList
+	t.Cleanup(func() { lv_router.GroupList = groups })
+	g := lv_router.New("/system/user")
+	g.GET("/:id", "system:user:view", func(c *gin.Context) { c.String(200, "ok") })
+	perms := PermissionProviderFunc(func(c *gin.Context) ([]string, error) {
+		if c.GetHeader("X-User") == "" {
+			return nil, ErrTokenMissing
+		}
+		return []string{c.GetHeader("X-User")}, nil
+	})
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RBACWith(perms))
+	for _, rt := range g.Router { //只注册本用例的路由，重复执行时不会重复注册
+		r.Group(g.RelativePath).Handle(rt.Method, rt.RelativePath, rt.HandlerFunc...)
+	}
+	r.GET("/open", func(c *gin.Context) { c.String(200, "ok") })
+	for _, tc := range []struct {
+		path, user string
+		want       int
+	}{
+		{"/system/user/1", "", http.StatusUnauthorized},
+		{"/system/user/1", "system:role:*", http.StatusForbidden},
+		{"/system/user/1", "system:user:*", http.StatusOK},
+		{"/open", "", http.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set("X-User", tc.user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatal(tc, w.Code)
+		}
+	}
+}
+
+func TestMatchPermission(t *testing.T) {
+	cases := []struct {
+		granted, required string
+		want              bool
+	}{
+		{"system:user:view", "system:user:view", true},
+		{"system:user:*", "system:user:view", true},
+		{"system:*", "system:user:view", true},
+		{"system:*:view", "system:role:view", true},
+		{"system:*:view", "system:role:edit", false},
+		{"*:*:*", "system:user:view", true},
+		{"*", "anything", true},
+		{"system:user", "system:user:view", false},
+		{"system:user:view", "system:user", false},
+		{"system:role:*", "system:user:view", false},
+	}
+	for _, tc := range cases {
+		if got := MatchPermission(tc.granted, tc.required); got != tc.want {
+			t.Errorf("MatchPermission(%q, %q) = %v", tc.granted, tc.required, got)
+		}
+	}
+}
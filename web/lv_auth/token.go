@@ -0,0 +1,385 @@
+package lv_auth
+
+import (
+	"crypto"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lostvip-com/lv_framework/lv_cache"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/satori/go.uuid"
+)
+
+// 令牌类型，刷新令牌不能用于访问接口
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+var (
+	ErrTokenMissing = errors.New("未登录或登录已过期")
+	ErrTokenInvalid = errors.New("Token无效，请重新登录")
+	ErrTokenExpired = errors.New("Token已过期，请重新登录")
+	ErrTokenRevoked = errors.New("Token已注销，请重新登录")
+	ErrTokenType    = errors.New("Token类型错误")
+	ErrTokenCheck   = errors.New("Token校验失败，请稍后重试")
+)
+
+// Claims 令牌中的用户信息
+type Claims struct {
+	UserId      int64          `json:"userId"`
+	Username    string         `json:"username"`
+	Realname    string         `json:"realname,omitempty"`
+	Phone       string         `json:"phone,omitempty"`
+	Roles       []string       `json:"roles,omitempty"`
+	Permissions []string       `json:"permissions,omitempty"`
+	Extra       map[string]any `json:"extra,omitempty"`
+	TokenType   string         `json:"typ"`
+	IssuedAtMs  int64          `json:"iatms,omitempty"` // 毫秒精度的签发时间，iat 只精确到秒
+	jwt.RegisteredClaims
+}
+
+// TokenPair 登录或刷新时返回给客户端的令牌
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // 访问令牌有效秒数
+}
+
+// Options 令牌配置，HS256 使用 Secret，RS256/EdDSA 使用私钥签名、公钥验证
+//
+//	application:
+//	  jwt:
+//	    algorithm: RS256          # HS256/RS256/EdDSA
+//	    secret: ${JWT_SECRET}     # HS256
+//	    private-key: keys/jwt.pem # RS256/EdDSA，PEM 文件路径或内容
+//	    public-key: keys/jwt.pub  # 只验证令牌的服务只需配置公钥
+//	    issuer: lv
+//	    audience: web
+//	    access-ttl: 30m
+//	    refresh-ttl: 168h
+//	    leeway: 30s               # 允许的时钟偏差
+type Options struct {
+	Algorithm  string
+	Secret     []byte
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+	Issuer     string
+	Audience   string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	Leeway     time.Duration
+	Cache      lv_cache.ICache // 保存注销的令牌，为nil时使用 lv_cache.GetCacheClient
+}
+
+// TokenManager 签发、校验、刷新和注销令牌
+type TokenManager struct {
+	opts   Options
+	method jwt.SigningMethod
+	parser *jwt.Parser
+}
+
+var (
+	defaultManager *TokenManager
+	defaultErr     error
+	defaultOnce    sync.Once
+)
+
+// Default 按 application.jwt 配置创建的令牌管理
+func Default() (*TokenManager, error) {
+	defaultOnce.Do(func() {
+		var opts Options
+		if opts, defaultErr = LoadOptions(); defaultErr == nil {
+			defaultManager, defaultErr = NewTokenManager(opts)
+		}
+	})
+	return defaultManager, defaultErr
+}
+
+// LoadOptions 读取 application.jwt 配置，密钥支持 ${ENV} 形式从环境变量读取
+func LoadOptions() (Options, error) {
+	cfg := lv_conf.Config()
+	opts := Options{
+		Algorithm:  strings.ToUpper(cfg.GetValueStr(lv_global.KEY_JWT + ".algorithm")),
+		Secret:     []byte(cfg.GetValueStr(lv_global.KEY_JWT + ".secret")),
+		Issuer:     cfg.GetValueStr(lv_global.KEY_JWT + ".issuer"),
+		Audience:   cfg.GetValueStr(lv_global.KEY_JWT + ".audience"),
+		AccessTTL:  lv_conf.Config().GetDuration(lv_global.KEY_JWT+".access-ttl", 30*time.Minute),
+		RefreshTTL: lv_conf.Config().GetDuration(lv_global.KEY_JWT+".refresh-ttl", 7*24*time.Hour),
+		Leeway:     lv_conf.Config().GetDuration(lv_global.KEY_JWT+".leeway", 30*time.Second),
+	}
+	if opts.Algorithm == "EDDSA" {
+		opts.Algorithm = jwt.SigningMethodEdDSA.Alg()
+	}
+	privatePem, err := readPem(cfg.GetValueStr(lv_global.KEY_JWT + ".private-key"))
+	if err != nil {
+		return opts, err
+	}
+	publicPem, err := readPem(cfg.GetValueStr(lv_global.KEY_JWT + ".public-key"))
+	if err != nil {
+		return opts, err
+	}
+	switch opts.Algorithm {
+	case "RS256":
+		if privatePem != nil {
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(privatePem)
+			if err != nil {
+				return opts, err
+			}
+			opts.PrivateKey, opts.PublicKey = key, &key.PublicKey
+		}
+		if publicPem != nil {
+			if opts.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPem); err != nil {
+				return opts, err
+			}
+		}
+	case "EdDSA":
+		if privatePem != nil {
+			key, err := jwt.ParseEdPrivateKeyFromPEM(privatePem)
+			if err != nil {
+				return opts, err
+			}
+			opts.PrivateKey = key
+			opts.PublicKey = key.(crypto.Signer).Public()
+		}
+		if publicPem != nil {
+			if opts.PublicKey, err = jwt.ParseEdPublicKeyFromPEM(publicPem); err != nil {
+				return opts, err
+			}
+		}
+	}
+	return opts, nil
+}
+
+// readPem 配置可以是PEM内容或文件路径
+func readPem(value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+// NewTokenManager 校验配置并创建令牌管理，未指定算法时为 HS256
+func NewTokenManager(opts Options) (*TokenManager, error) {
+	if opts.Algorithm == "" {
+		opts.Algorithm = jwt.SigningMethodHS256.Alg()
+	}
+	method := jwt.GetSigningMethod(opts.Algorithm)
+	switch method {
+	case jwt.SigningMethodHS256:
+		if len(opts.Secret) < 32 {
+			return nil, errors.New("jwt secret must be at least 32 bytes")
+		}
+	case jwt.SigningMethodRS256, jwt.SigningMethodEdDSA:
+		if opts.PublicKey == nil {
+			return nil, errors.New("jwt public-key or private-key is required for " + opts.Algorithm)
+		}
+	default:
+		return nil, errors.New("unsupported jwt algorithm: " + opts.Algorithm)
+	}
+	if opts.AccessTTL <= 0 {
+		opts.AccessTTL = 30 * time.Minute
+	}
+	if opts.RefreshTTL <= 0 {
+		opts.RefreshTTL = 7 * 24 * time.Hour
+	}
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods([]string{method.Alg()}), jwt.WithLeeway(opts.Leeway),
+		jwt.WithExpirationRequired(), jwt.WithIssuedAt()}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	return &TokenManager{opts: opts, method: method, parser: jwt.NewParser(parserOpts...)}, nil
+}
+
+func (m *TokenManager) cache() lv_cache.ICache {
+	if m.opts.Cache != nil {
+		return m.opts.Cache
+	}
+	return lv_cache.GetCacheClient()
+}
+
+func (m *TokenManager) signKey() (interface{}, error) {
+	if m.method == jwt.SigningMethodHS256 {
+		return m.opts.Secret, nil
+	}
+	if m.opts.PrivateKey == nil {
+		return nil, errors.New("jwt private-key is required to sign tokens")
+	}
+	return m.opts.PrivateKey, nil
+}
+
+func (m *TokenManager) verifyKey(*jwt.Token) (interface{}, error) {
+	if m.method == jwt.SigningMethodHS256 {
+		return m.opts.Secret, nil
+	}
+	return m.opts.PublicKey, nil
+}
+
+func (m *TokenManager) sign(claims Claims, typ string, ttl time.Duration) (string, error) {
+	key, err := m.signKey()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims.TokenType = typ
+	claims.ID = uuid.NewV4().String()
+	claims.Issuer = m.opts.Issuer
+	if m.opts.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.opts.Audience}
+	}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.IssuedAtMs = now.UnixMilli()
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	return jwt.NewWithClaims(m.method, &claims).SignedString(key)
+}
+
+// Generate 签发访问令牌和刷新令牌
+func (m *TokenManager) Generate(claims *Claims) (*TokenPair, error) {
+	access, err := m.sign(*claims, AccessToken, m.opts.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := m.sign(*claims, RefreshToken, m.opts.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: refresh, TokenType: "Bearer",
+		ExpiresIn: int64(m.opts.AccessTTL / time.Second)}, nil
+}
+
+// Parse 校验访问令牌
+func (m *TokenManager) Parse(token string) (*Claims, error) {
+	return m.parse(token, AccessToken)
+}
+
+func (m *TokenManager) parse(token, typ string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrTokenMissing
+	}
+	claims := new(Claims)
+	if _, err := m.parser.ParseWithClaims(token, claims, m.verifyKey); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrTokenInvalid
+	}
+	if claims.TokenType != typ {
+		return nil, ErrTokenType
+	}
+	revoked, err := m.revoked(claims)
+	if err != nil { //无法确认是否已注销时拒绝令牌
+		lv_log.Error("check token revoked error:", err)
+		return nil, ErrTokenCheck
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌，旧的刷新令牌同时注销，只能使用一次
+func (m *TokenManager) Refresh(refreshToken string) (*TokenPair, error) {
+	claims, err := m.parse(refreshToken, RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := m.revoke(claims, true)
+	if err != nil {
+		return nil, err
+	}
+	if !ok { //并发使用同一个刷新令牌时只有一个能成功
+		return nil, ErrTokenRevoked
+	}
+	return m.Generate(claims)
+}
+
+const (
+	revokedKeyPrefix = "lv_jwt:revoked:" // 注销的令牌ID，保存到令牌过期
+	userKeyPrefix    = "lv_jwt:user:"    // 用户注销时间，之前签发的令牌全部失效
+)
+
+// Revoke 注销令牌，令牌过期前都会被拒绝
+func (m *TokenManager) Revoke(claims *Claims) error {
+	_, err := m.revoke(claims, false)
+	return err
+}
+
+// revoke onlyOnce 为true时只有第一次注销返回true，用于刷新令牌只能使用一次
+func (m *TokenManager) revoke(claims *Claims, onlyOnce bool) (bool, error) {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return false, ErrTokenInvalid
+	}
+	ttl := time.Until(claims.ExpiresAt.Time) + m.opts.Leeway
+	if ttl <= 0 {
+		return !onlyOnce, nil
+	}
+	if onlyOnce {
+		return m.cache().SetNX(revokedKeyPrefix+claims.ID, "1", ttl)
+	}
+	return true, m.cache().Set(revokedKeyPrefix+claims.ID, "1", ttl)
+}
+
+// RevokeToken 注销令牌字符串，退出登录时访问令牌和刷新令牌都应注销
+func (m *TokenManager) RevokeToken(token string) error {
+	claims := new(Claims)
+	if _, err := m.parser.ParseWithClaims(token, claims, m.verifyKey); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil
+		}
+		return ErrTokenInvalid
+	}
+	return m.Revoke(claims)
+}
+
+// RevokeUser 注销用户当前所有的令牌，用于修改密码、踢人等
+func (m *TokenManager) RevokeUser(userId int64) error {
+	return m.cache().Set(userKeyPrefix+strconv.FormatInt(userId, 10), strconv.FormatInt(time.Now().UnixMilli(), 10), m.opts.RefreshTTL+m.opts.Leeway)
+}
+
+// revoked 令牌是否已注销，注销用户之前签发的令牌同样视为已注销，缓存读取失败时返回错误
+func (m *TokenManager) revoked(claims *Claims) (bool, error) {
+	cache := m.cache()
+	if _, err := cache.Get(revokedKeyPrefix + claims.ID); err == nil {
+		return true, nil
+	} else if !lv_cache.IsNil(err) {
+		return false, err
+	}
+	v, err := cache.Get(userKeyPrefix + strconv.FormatInt(claims.UserId, 10))
+	if lv_cache.IsNil(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	revokedAt, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return claims.issuedAtMs() < revokedAt, nil
+}
+
+// issuedAtMs 毫秒精度的签发时间，没有 iatms 的令牌按 iat 所在秒的开始计算
+func (c *Claims) issuedAtMs() int64 {
+	if c.IssuedAtMs > 0 {
+		return c.IssuedAtMs
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.UnixMilli()
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package lv_auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+)
+
+func TestTokenAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	cases := []Options{
+		{Algorithm: "HS256", Secret: []byte("0123456789abcdef0123456789abcdef")},
+		{Algorithm: "RS256", PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey},
+		{Algorithm: "EdDSA", PrivateKey: edKey, PublicKey: edPub},
+	}
+	for _, opts := range cases {
+		opts.Cache = lv_ram.NewRamCacheClient()
+		opts.Issuer = "lv"
+		m, err := NewTokenManager(opts)
+		if err != nil {
+			t.Fatal(opts.Algorithm, err)
+		}
+		pair, err := m.Generate(&Claims{UserId: 7, Username: "admin", Permissions: []string{"system:user:view"}})
+		if err != nil {
+			t.Fatal(opts.Algorithm, err)
+		}
+		claims, err := m.Parse(pair.AccessToken)
+		if err != nil || claims.UserId != 7 || claims.Permissions[0] != "system:user:view" {
+			t.Fatal(opts.Algorithm, claims, err)
+		}
+		if _, err = m.Parse(pair.RefreshToken); !errors.Is(err, ErrTokenType) {
+			t.Fatal(opts.Algorithm, err)
+		}
+	}
+	if _, err := NewTokenManager(Options{Secret: []byte("short")}); err == nil {
+		t.Fatal("short secret accepted")
+	}
+}
+
+func TestTokenRefreshRevoke(t *testing.T) {
+	m, _ := NewTokenManager(Options{Secret: []byte("0123456789abcdef0123456789abcdef"), Cache: lv_ram.NewRamCacheClient()})
+	pair, _ := m.Generate(&Claims{UserId: 1, Username: "admin"})
+	next, err := m.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Refresh(pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) { //刷新令牌只能使用一次
+		t.Fatal(err)
+	}
+	if err = m.RevokeToken(next.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Parse(next.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	m.RevokeUser(1)
+	if _, err = m.Parse(pair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	relogin, _ := m.Generate(&Claims{UserId: 1, Username: "admin"}) //注销后同一秒内重新登录
+	if _, err = m.Parse(relogin.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+
+	var ok int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Refresh(relogin.RefreshToken); err == nil {
+				atomic.AddInt32(&ok, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if ok != 1 {
+		t.Fatal(ok)
+	}
+
+	m.opts.Cache = downCache{lv_ram.NewRamCacheClient()}
+	if _, err = m.Parse(relogin.AccessToken); !errors.Is(err, ErrTokenCheck) { //缓存不可用时拒绝令牌
+		t.Fatal(err)
+	}
+}
+
+type downCache struct {
+	*lv_ram.RamCacheClient
+}
+
+func (downCache) Get(key string) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestTokenLeeway(t *testing.T) {
+	opts := Options{Secret: []byte("0123456789abcdef0123456789abcdef"), Cache: lv_ram.NewRamCacheClient(),
+		AccessTTL: time.Millisecond}
+	m, _ := NewTokenManager(opts)
+	pair, _ := m.Generate(&Claims{UserId: 1})
+	time.Sleep(1100 * time.Millisecond) //exp 精确到秒
+	if _, err := m.Parse(pair.AccessToken); !errors.Is(err, ErrTokenExpired) {
+		t.Fatal(err)
+	}
+	opts.Leeway = time.Minute
+	m, _ = NewTokenManager(opts)
+	if _, err := m.Parse(pair.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	m, _ := NewTokenManager(Options{Secret: []byte("0123456789abcdef0123456789abcdef"), Cache: lv_ram.NewRamCacheClient()})
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", m.Middleware(), func(c *gin.Context) {
+		claims, _ := FromContext(c.Request.Context())
+		c.String(http.StatusOK, GetClaims(c).Username+claims.Username)
+	})
+	pair, _ := m.Generate(&Claims{UserId: 1, Username: "admin"})
+	for token, want := range map[string]int{"": 401, "bad": 401, pair.AccessToken: 200} {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want || (want == 200 && w.Body.String() != "adminadmin") {
+			t.Fatal(token, w.Code, w.Body.String())
+		}
+	}
+}
@@ -20,7 +20,7 @@ const (
 
 // 通用api响应
 type CommonRes struct {
-	Code int         `json:"code"` //响应编码 0 成功 500 错误 403 无权限  -1  失败
+	Code int         `json:"code"` //响应编码 200 成功 500 错误 403 无权限 -1 失败，见 errcode.go
 	Msg  string      `json:"msg"`  //消息
 	Data interface{} `json:"data"` //数据内容
 }
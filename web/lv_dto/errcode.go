@@ -0,0 +1,201 @@
+package lv_dto
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+)
+
+// ErrorCode 错误码：响应中的 code、对应的 HTTP 状态码、国际化消息的 key 和默认消息
+type ErrorCode struct {
+	Code       int
+	HttpStatus int
+	MsgKey     string
+	Msg        string
+}
+
+var (
+	codeLock sync.RWMutex
+	codes    = make(map[int]ErrorCode)
+)
+
+// RegisterCode 登记错误码，通常在包级变量中定义，code 重复时 panic
+//
+//	var CodeUserNotFound = lv_dto.RegisterCode(10404, http.StatusNotFound, "user.not_found", "用户不存在")
+func RegisterCode(code, httpStatus int, msgKey, msg string) ErrorCode {
+	codeLock.Lock()
+	defer codeLock.Unlock()
+	if old, ok := codes[code]; ok {
+		panic(fmt.Sprintf("error code %d already registered: %s", code, old.MsgKey))
+	}
+	c := ErrorCode{Code: code, HttpStatus: httpStatus, MsgKey: msgKey, Msg: msg}
+	codes[code] = c
+	return c
+}
+
+// LookupCode 查询已登记的错误码
+func LookupCode(code int) (ErrorCode, bool) {
+	codeLock.RLock()
+	defer codeLock.RUnlock()
+	c, ok := codes[code]
+	return c, ok
+}
+
+// 框架内置的错误码，与原有的 SUCCESS/ERROR/UNAUTHORIZED/FAIL 一致
+var (
+	CodeSuccess         = RegisterCode(SUCCESS, http.StatusOK, "success", "success")
+	CodeError           = RegisterCode(ERROR, http.StatusInternalServerError, "error.internal", "系统错误")
+	CodeForbidden       = RegisterCode(UNAUTHORIZED, http.StatusForbidden, "error.forbidden", "没有权限")
+	CodeFail            = RegisterCode(FAIL, http.StatusBadRequest, "error.fail", "操作失败")
+	CodeLegacyFail      = RegisterCode(1, http.StatusBadRequest, "error.fail", "操作失败") //Resp.Fail 使用的错误码
+	CodeUnauthorized    = RegisterCode(http.StatusUnauthorized, http.StatusUnauthorized, "error.unauthorized", "未登录或登录已过期")
+	CodeNotFound        = RegisterCode(http.StatusNotFound, http.StatusNotFound, "error.not_found", "资源不存在")
+	CodeTooManyRequests = RegisterCode(http.StatusTooManyRequests, http.StatusTooManyRequests, "error.too_many_requests", "请求过于频繁，请稍后再试")
+)
+
+func init() {
+	RegisterMessages("en", map[string]string{
+		"error.internal":          "Internal server error",
+		"error.forbidden":         "Permission denied",
+		"error.fail":              "Operation failed",
+		"error.unauthorized":      "Not logged in or session expired",
+		"error.not_found":         "Resource not found",
+		"error.too_many_requests": "Too many requests, please try again later",
+	})
+}
+
+// New 创建该错误码的业务错误，msg 为空时使用国际化消息或默认消息
+func (c ErrorCode) New(msg ...string) *BizError {
+	return &BizError{ErrorCode: c, Message: strings.Join(msg, "")}
+}
+
+// Wrap 创建业务错误并保留原始错误，便于 errors.Is/As 判断和日志记录
+func (c ErrorCode) Wrap(err error, msg ...string) *BizError {
+	return &BizError{ErrorCode: c, Message: strings.Join(msg, ""), Err: err}
+}
+
+// BizError 业务错误，可以直接 return 或 panic，RecoverError 和 lv_router.Handle 会按错误码返回
+type BizError struct {
+	ErrorCode
+	Message string //覆盖默认消息
+	Data    any
+	Err     error
+}
+
+func (e *BizError) Error() string {
+	msg := e.GetMsg()
+	if e.Err != nil && e.Message == "" {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *BizError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BizError) GetCode() int {
+	return e.Code
+}
+
+func (e *BizError) GetMsg() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.ErrorCode.Msg
+}
+
+// WithData 设置响应中的 data，例如参数校验失败的字段
+func (e *BizError) WithData(data any) *BizError {
+	e.Data = data
+	return e
+}
+
+// Is 错误码相同即认为相同，例如 errors.Is(err, lv_dto.CodeNotFound.New())
+func (e *BizError) Is(target error) bool {
+	t, ok := target.(*BizError)
+	return ok && t.Code == e.Code
+}
+
+// ErrorResponse 将错误转换为 HTTP 状态码和响应，lang 为请求头 Accept-Language
+// BizError 和实现了 GetCode() int 的错误使用其错误码，其它错误为 CodeError
+func ErrorResponse(err error, lang string) (int, *CommonRes) {
+	var biz *BizError
+	if errors.As(err, &biz) {
+		msg := biz.Message
+		if msg == "" {
+			msg = Message(lang, biz.MsgKey, biz.ErrorCode.Msg)
+		}
+		return StatusOf(biz.ErrorCode), &CommonRes{Code: biz.Code, Msg: msg, Data: biz.Data}
+	}
+	var coded interface{ GetCode() int }
+	if errors.As(err, &coded) {
+		return StatusOfCode(coded.GetCode()), &CommonRes{Code: coded.GetCode(), Msg: err.Error()}
+	}
+	return StatusOf(CodeError), &CommonRes{Code: CodeError.Code, Msg: err.Error()}
+}
+
+// UseHttpStatus 是否返回真实的 HTTP 状态码，默认 false 与原来一样总是返回 200，错误信息只在 code 中
+func UseHttpStatus() bool {
+	cfg := lv_conf.Config()
+	return cfg != nil && cfg.GetBool(lv_global.KEY_RESPONSE_HTTP_STATUS)
+}
+
+// StatusOf 错误码对应的 HTTP 状态码，未开启 UseHttpStatus 时为 200
+func StatusOf(c ErrorCode) int {
+	if c.HttpStatus == 0 || !UseHttpStatus() {
+		return http.StatusOK
+	}
+	return c.HttpStatus
+}
+
+// StatusOfCode 按 code 查询 HTTP 状态码，未登记的 code 视为 CodeError
+func StatusOfCode(code int) int {
+	c, ok := LookupCode(code)
+	if !ok {
+		c = CodeError
+	}
+	return StatusOf(c)
+}
+
+var (
+	messageLock sync.RWMutex
+	messages    = make(map[string]map[string]string) // lang -> key -> 消息
+)
+
+// RegisterMessages 登记某种语言的消息，lang 如 zh、en
+func RegisterMessages(lang string, msgs map[string]string) {
+	messageLock.Lock()
+	defer messageLock.Unlock()
+	lang = strings.ToLower(lang)
+	if messages[lang] == nil {
+		messages[lang] = make(map[string]string)
+	}
+	for k, v := range msgs {
+		messages[lang][k] = v
+	}
+}
+
+// Message 按 Accept-Language 中的语言顺序查找消息，都没有时返回 def
+func Message(acceptLanguage, key, def string) string {
+	messageLock.RLock()
+	defer messageLock.RUnlock()
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		lang, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang = strings.ToLower(lang)
+		if msg, ok := messages[lang][key]; ok {
+			return msg
+		}
+		if base, _, ok := strings.Cut(lang, "-"); ok {
+			if msg, ok := messages[base][key]; ok {
+				return msg
+			}
+		}
+	}
+	return def
+}
@@ -0,0 +1,45 @@
+package lv_dto
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var codeUserNotFound = RegisterCode(10404, http.StatusNotFound, "user.not_found", "用户不存在")
+
+func TestErrorResponse(t *testing.T) {
+	RegisterMessages("en", map[string]string{"user.not_found": "User not found"})
+	err := fmt.Errorf("load user: %w", codeUserNotFound.Wrap(errors.New("record not found")))
+	if !errors.Is(err, codeUserNotFound.New()) || errors.Is(err, CodeNotFound.New()) {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		err  error
+		lang string
+		code int
+		msg  string
+	}{
+		{err, "en-US,en;q=0.9", 10404, "User not found"},
+		{err, "zh-CN", 10404, "用户不存在"},
+		{codeUserNotFound.New("用户 7 不存在"), "en", 10404, "用户 7 不存在"},
+		{CodeForbidden.New(), "", UNAUTHORIZED, "没有权限"},
+		{errors.New("boom"), "", ERROR, "boom"},
+	}
+	for _, tc := range cases {
+		status, res := ErrorResponse(tc.err, tc.lang)
+		if status != http.StatusOK || res.Code != tc.code || res.Msg != tc.msg { //未开启 http-status 时总是 200
+			t.Fatal(tc.err, status, res)
+		}
+	}
+	if c, ok := LookupCode(10404); !ok || c.HttpStatus != http.StatusNotFound {
+		t.Fatal(c)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate code registered")
+		}
+	}()
+	RegisterCode(10404, http.StatusNotFound, "dup", "dup")
+}
@@ -2,6 +2,8 @@ package lv_dto
 
 import (
 	"math"
+
+	"github.com/lostvip-com/lv_framework/utils/lv_sql"
 )
 
 const PageSize int = 15
@@ -12,8 +14,8 @@ type Paging struct {
 	PageNum       int    `form:"pageNum"  json:"pageNum"`  //当前页
 	PageSize      int    `form:"pageSize" json:"pageSize"` //每页条数
 	Total         int64  `form:"total"    json:"total"`    //每页条数//总条数
-	OrderByColumn string `json:"orderByColumn,omitempty"`
-	IsAsc         string `json:"isAsc,omitempty"` //
+	OrderByColumn string `json:"orderByColumn,omitempty"`  //排序字段，需在dto中使用 lv_sort 标记白名单
+	IsAsc         string `json:"isAsc,omitempty"`          //排序方向 asc/desc
 
 	PageCount int //总页数
 	StartNum  int //起始行
@@ -39,6 +41,20 @@ func (p *Paging) GetPageSize() int {
 	return p.PageSize
 }
 
+// GetSort 按dto上 lv_sort 标记的白名单校验排序参数，dto 通常为嵌入了 Paging 的请求结构体
+func (p *Paging) GetSort(dto any) (lv_sql.SortSpec, error) {
+	return lv_sql.ParseSort(dto, p.OrderByColumn, p.IsAsc)
+}
+
+// GetOrderBy 生成方言转义后的 order by 子句，未传排序参数时返回空串
+func (p *Paging) GetOrderBy(dto any, driver string) (string, error) {
+	spec, err := p.GetSort(dto)
+	if err != nil {
+		return "", err
+	}
+	return spec.OrderBy(driver), nil
+}
+
 // 创建分页
 func CreatePaging(pageNum, pagesize int, total int64) *Paging {
 	if pageNum < 1 {
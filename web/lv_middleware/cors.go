@@ -0,0 +1,318 @@
+package lv_middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+)
+
+var (
+	defaultAllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	defaultAllowHeaders = []string{"authorization", "origin", "content-type", "accept"}
+)
+
+// CorsPolicy 跨域策略，AllowOrigins 支持 * 和通配子域名，例如 https://*.example.com
+// AllowHeaders 为 * 时允许请求的所有头
+type CorsPolicy struct {
+	AllowOrigins     []string      `mapstructure:"allow-origins"`
+	AllowMethods     []string      `mapstructure:"allow-methods"`
+	AllowHeaders     []string      `mapstructure:"allow-headers"`
+	ExposeHeaders    []string      `mapstructure:"expose-headers"`
+	AllowCredentials *bool         `mapstructure:"allow-credentials"`
+	MaxAge           time.Duration `mapstructure:"max-age"`
+}
+
+// CorsGroup 路由组的跨域策略，未配置的字段沿用全局配置
+// Path 为请求路径(不含 context-path)，以 * 结尾时按前缀匹配
+type CorsGroup struct {
+	Path       string `mapstructure:"path"`
+	CorsPolicy `mapstructure:",squash"`
+}
+
+// CorsConfig 跨域配置，未开启时与之前的 Options 一致，只应答预检请求并允许所有来源，其它请求不添加跨域头
+//
+//	application:
+//	  cors:
+//	    enabled: true
+//	    allow-origins: [https://admin.example.com, https://*.example.com]
+//	    allow-headers: [authorization, content-type]
+//	    expose-headers: [RateLimit-Remaining]
+//	    allow-credentials: true
+//	    max-age: 12h
+//	    groups:
+//	      - path: /open/*
+//	        allow-origins: ["*"]
+//	        allow-credentials: false
+type CorsConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	CorsPolicy `mapstructure:",squash"`
+	Groups     []CorsGroup `mapstructure:"groups"`
+}
+
+// LoadCorsConfig 读取 application.cors 配置
+func LoadCorsConfig() *CorsConfig {
+	cfg := new(CorsConfig)
+	cfg.Enabled = lv_conf.Config().GetBool(lv_global.KEY_CORS + ".enabled") //同时确保配置已加载
+	if !cfg.Enabled {
+		cfg.AllowOrigins = []string{"*"}
+		return cfg
+	}
+	if err := lv_conf.Config().GetVipperCfg().UnmarshalKey(lv_global.KEY_CORS, cfg); err != nil {
+		lv_log.Error("cors config error:", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		panic("cors 配置错误: " + err.Error())
+	}
+	return cfg
+}
+
+// Validate 检查全局策略和每个路由组合并后的策略，允许所有来源时不能携带凭证
+func (cfg *CorsConfig) Validate() error {
+	if err := cfg.CorsPolicy.Validate(); err != nil {
+		return err
+	}
+	for _, g := range cfg.Groups {
+		if err := g.CorsPolicy.inherit(&cfg.CorsPolicy).Validate(); err != nil {
+			return fmt.Errorf("%s: %w", g.Path, err)
+		}
+	}
+	return nil
+}
+
+// Validate allow-origins 为 * 时不能同时开启 allow-credentials，否则任意网站都能携带用户凭证访问
+func (p CorsPolicy) Validate() error {
+	if p.AllowCredentials == nil || !*p.AllowCredentials {
+		return nil
+	}
+	for _, o := range p.AllowOrigins {
+		if strings.TrimSpace(o) == "*" {
+			return errors.New("allow-origins * can not be used with allow-credentials")
+		}
+	}
+	return nil
+}
+
+var (
+	corsOnce    sync.Once
+	corsHandler func(c *gin.Context) bool
+)
+
+// Cors 按 application.cors 配置处理跨域
+func Cors() gin.HandlerFunc {
+	return CorsWith(LoadCorsConfig())
+}
+
+// CorsWith 使用指定的配置处理跨域：预检请求直接返回204，来源、方法或请求头不允许时返回403；
+// 其他请求补充跨域响应头后继续执行，来源不允许时不返回跨域头，由浏览器拦截，配置错误时 panic
+func CorsWith(cfg *CorsConfig) gin.HandlerFunc {
+	if err := cfg.Validate(); err != nil {
+		panic("cors 配置错误: " + err.Error())
+	}
+	handle := corsWith(cfg)
+	return func(c *gin.Context) {
+		if handle(c) {
+			c.Next()
+		}
+	}
+}
+
+// corsWith 返回 false 表示请求已结束
+func corsWith(cfg *CorsConfig) func(c *gin.Context) bool {
+	contextPath := ""
+	if lv_conf.Config() != nil {
+		contextPath = strings.TrimSuffix(lv_conf.Config().GetContextPath(), "/")
+	}
+	global := cfg.CorsPolicy.compile()
+	global.preflightOnly = !cfg.Enabled
+	groups := make([]*corsRule, len(cfg.Groups))
+	for i, g := range cfg.Groups {
+		groups[i] = g.CorsPolicy.inherit(&cfg.CorsPolicy).compile()
+		groups[i].preflightOnly = !cfg.Enabled
+	}
+	return func(c *gin.Context) bool {
+		rule := global
+		path := strings.TrimPrefix(c.Request.URL.Path, contextPath)
+		for i, g := range cfg.Groups {
+			if g.Path == path || (strings.HasSuffix(g.Path, "*") && strings.HasPrefix(path, strings.TrimSuffix(g.Path, "*"))) {
+				rule = groups[i]
+				break
+			}
+		}
+		return rule.handle(c)
+	}
+}
+
+// inherit 未配置的字段使用 parent 的值
+func (p CorsPolicy) inherit(parent *CorsPolicy) CorsPolicy {
+	if p.AllowOrigins == nil {
+		p.AllowOrigins = parent.AllowOrigins
+	}
+	if p.AllowMethods == nil {
+		p.AllowMethods = parent.AllowMethods
+	}
+	if p.AllowHeaders == nil {
+		p.AllowHeaders = parent.AllowHeaders
+	}
+	if p.ExposeHeaders == nil {
+		p.ExposeHeaders = parent.ExposeHeaders
+	}
+	if p.AllowCredentials == nil {
+		p.AllowCredentials = parent.AllowCredentials
+	}
+	if p.MaxAge == 0 {
+		p.MaxAge = parent.MaxAge
+	}
+	return p
+}
+
+// corsRule 预处理后的策略
+type corsRule struct {
+	anyOrigin     bool
+	origins       map[string]bool
+	wildcards     [][2]string //通配子域名的前缀和后缀
+	methods       string
+	methodSet     map[string]bool
+	anyHeader     bool
+	headers       string
+	headerSet     map[string]bool
+	expose        string
+	credentials   bool
+	maxAge        string
+	preflightOnly bool //未开启跨域配置时只应答预检请求，普通请求不添加跨域头，避免任意网站读取响应
+}
+
+func (p CorsPolicy) compile() *corsRule {
+	r := &corsRule{origins: make(map[string]bool), methodSet: make(map[string]bool), headerSet: make(map[string]bool)}
+	for _, o := range p.AllowOrigins {
+		o = strings.ToLower(strings.TrimSpace(o))
+		if o == "*" {
+			r.anyOrigin = true
+		} else if i := strings.Index(o, "*"); i >= 0 {
+			r.wildcards = append(r.wildcards, [2]string{o[:i], o[i+1:]})
+		} else if o != "" {
+			r.origins[o] = true
+		}
+	}
+	methods := make([]string, 0, len(defaultAllowMethods))
+	for _, m := range p.AllowMethods {
+		methods = append(methods, strings.ToUpper(strings.TrimSpace(m)))
+	}
+	if len(methods) == 0 {
+		methods = append(methods, defaultAllowMethods...)
+	}
+	for _, m := range methods {
+		r.methodSet[m] = true
+	}
+	r.methods = strings.Join(methods, ",")
+	headers := p.AllowHeaders
+	if len(headers) == 0 {
+		headers = defaultAllowHeaders
+	}
+	for _, h := range headers {
+		h = strings.ToLower(strings.TrimSpace(h))
+		r.anyHeader = r.anyHeader || h == "*"
+		r.headerSet[h] = true
+	}
+	r.headers = strings.Join(headers, ", ")
+	r.expose = strings.Join(p.ExposeHeaders, ", ")
+	r.credentials = p.AllowCredentials != nil && *p.AllowCredentials
+	if p.MaxAge > 0 {
+		r.maxAge = strconv.Itoa(int(p.MaxAge.Seconds()))
+	}
+	return r
+}
+
+func (r *corsRule) allowOrigin(origin string) bool {
+	if r.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if r.origins[origin] {
+		return true
+	}
+	for _, w := range r.wildcards {
+		if len(origin) > len(w[0])+len(w[1]) && strings.HasPrefix(origin, w[0]) && strings.HasSuffix(origin, w[1]) {
+			sub := origin[len(w[0]) : len(origin)-len(w[1])]
+			if !strings.ContainsAny(sub, "/:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handle 写入跨域响应头，返回 false 表示请求已结束
+func (r *corsRule) handle(c *gin.Context) bool {
+	h := c.Writer.Header()
+	origin := c.GetHeader("Origin")
+	preflight := c.Request.Method == http.MethodOptions && origin != "" && c.GetHeader("Access-Control-Request-Method") != ""
+	if r.preflightOnly && !preflight {
+		return true
+	}
+	if !r.anyOrigin || r.credentials { //响应随 Origin 变化，避免缓存给其他来源
+		h.Add("Vary", "Origin")
+	}
+	if preflight {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+	if origin == "" {
+		return true
+	}
+	if !r.allowOrigin(origin) {
+		if preflight {
+			c.AbortWithStatus(http.StatusForbidden)
+			return false
+		}
+		return true
+	}
+	if r.anyOrigin && !r.credentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if r.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		if r.expose != "" {
+			h.Set("Access-Control-Expose-Headers", r.expose)
+		}
+		return true
+	}
+
+	if !r.methodSet[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))] {
+		c.AbortWithStatus(http.StatusForbidden)
+		return false
+	}
+	reqHeaders := c.GetHeader("Access-Control-Request-Headers")
+	allowHeaders := r.headers
+	if r.anyHeader {
+		allowHeaders = reqHeaders
+	} else if reqHeaders != "" {
+		for _, name := range strings.Split(reqHeaders, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" && !r.headerSet[name] {
+				c.AbortWithStatus(http.StatusForbidden)
+				return false
+			}
+		}
+	}
+	h.Set("Access-Control-Allow-Methods", r.methods)
+	if allowHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", allowHeaders)
+	}
+	if r.maxAge != "" {
+		h.Set("Access-Control-Max-Age", r.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+	return false
+}
@@ -0,0 +1,114 @@
+package lv_middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCors(t *testing.T) {
+	yes, no := true, false
+	cfg := &CorsConfig{
+		Enabled: true,
+		CorsPolicy: CorsPolicy{
+			AllowOrigins:     []string{"https://admin.example.com", "https://*.example.com"},
+			ExposeHeaders:    []string{"RateLimit-Remaining"},
+			AllowCredentials: &yes,
+			MaxAge:           time.Hour,
+		},
+		Groups: []CorsGroup{{Path: "/open/*", CorsPolicy: CorsPolicy{AllowOrigins: []string{"*"}, AllowCredentials: &no}}},
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CorsWith(cfg))
+	r.GET("/api", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/open/info", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	do := func(method, path, origin string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("GET", "/api", "https://a.b.example.com")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://a.b.example.com" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" || w.Header().Get("Access-Control-Expose-Headers") != "RateLimit-Remaining" ||
+		w.Header().Get("Vary") != "Origin" {
+		t.Fatal(w.Code, w.Header())
+	}
+	for _, origin := range []string{"https://example.com", "http://a.example.com", "https://evil.com/.example.com", "https://example.com.evil.com"} {
+		if w = do("GET", "/api", origin); w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatal(origin, w.Header())
+		}
+	}
+
+	w = do("OPTIONS", "/api", "https://admin.example.com", "Access-Control-Request-Method", "PUT", "Access-Control-Request-Headers", "Content-Type, Authorization")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://admin.example.com" ||
+		w.Header().Get("Access-Control-Max-Age") != "3600" || len(w.Header().Values("Vary")) != 3 {
+		t.Fatal(w.Code, w.Header())
+	}
+	if w = do("OPTIONS", "/api", "https://admin.example.com", "Access-Control-Request-Method", "PUT", "Access-Control-Request-Headers", "X-Secret"); w.Code != http.StatusForbidden {
+		t.Fatal("header not allowed", w.Code)
+	}
+	if w = do("OPTIONS", "/api", "https://evil.com", "Access-Control-Request-Method", "GET"); w.Code != http.StatusForbidden {
+		t.Fatal("origin not allowed", w.Code)
+	}
+
+	w = do("GET", "/open/info", "https://evil.com")
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Allow-Credentials") != "" ||
+		w.Header().Get("Access-Control-Expose-Headers") != "RateLimit-Remaining" || w.Header().Get("Vary") != "" {
+		t.Fatal(w.Header())
+	}
+}
+
+func TestCorsDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &CorsConfig{CorsPolicy: CorsPolicy{AllowOrigins: []string{"*"}}} //与 LoadCorsConfig 未开启时一致
+	r := gin.New()
+	r.Use(CorsWith(cfg))
+	r.GET("/x", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	req := httptest.NewRequest("GET", "/x", nil)
+	req.Header.Set("Origin", "https://evil.test")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal(w.Code, w.Header())
+	}
+	req = httptest.NewRequest("OPTIONS", "/x", nil)
+	req.Header.Set("Origin", "https://evil.test")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatal(w.Code, w.Header())
+	}
+}
+
+func TestCorsValidate(t *testing.T) {
+	yes := true
+	cfg := &CorsConfig{Enabled: true, CorsPolicy: CorsPolicy{AllowOrigins: []string{"*"}, AllowCredentials: &yes}}
+	if cfg.Validate() == nil {
+		t.Fatal("* with credentials accepted")
+	}
+	//路由组继承全局的 allow-credentials
+	cfg = &CorsConfig{Enabled: true, CorsPolicy: CorsPolicy{AllowOrigins: []string{"https://admin.example.com"}, AllowCredentials: &yes},
+		Groups: []CorsGroup{{Path: "/open/*", CorsPolicy: CorsPolicy{AllowOrigins: []string{"*"}}}}}
+	if cfg.Validate() == nil {
+		t.Fatal("group * with inherited credentials accepted")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("CorsWith should panic")
+		}
+	}()
+	CorsWith(cfg)
+}
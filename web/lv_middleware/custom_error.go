@@ -17,16 +17,17 @@
 package lv_middleware
 
 import (
-	"net/http"
+	"encoding/json"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lostvip-com/lv_framework/lv_log"
 	"github.com/lostvip-com/lv_framework/utils/lv_err"
 	"github.com/lostvip-com/lv_framework/web/lv_dto"
-	"github.com/spf13/cast"
 )
 
+// RecoverError 将 panic 转换为响应，BizError 按错误码返回，
+// 开启 application.response.http-status 后使用错误码对应的 HTTP 状态码，否则与原来一样总是 200
 func RecoverError(c *gin.Context) {
 	defer func() {
 		err := recover()
@@ -35,22 +36,29 @@ func RecoverError(c *gin.Context) {
 			case string:
 				if strings.HasPrefix(errTypeObj, "{") {
 					c.Header("Content-Type", "application/json; charset=utf-8")
-					c.String(http.StatusOK, errTypeObj)
+					c.String(lv_dto.StatusOfCode(jsonCode(errTypeObj)), errTypeObj)
 					c.Abort()
 				} else {
-					c.AbortWithStatusJSON(http.StatusOK, &lv_dto.CommonRes{Code: 500, Msg: errTypeObj})
+					c.AbortWithStatusJSON(lv_dto.StatusOf(lv_dto.CodeError), &lv_dto.CommonRes{Code: 500, Msg: errTypeObj})
 				}
 			case lv_dto.Resp: //封装过的
-				c.AbortWithStatusJSON(http.StatusOK, errTypeObj)
+				c.AbortWithStatusJSON(lv_dto.StatusOfCode(errTypeObj.Code), errTypeObj)
+			case *lv_dto.BizError: //业务错误
+				if errTypeObj.Err != nil {
+					lv_log.Error(c, "BizError: ", errTypeObj)
+				}
+				status, res := lv_dto.ErrorResponse(errTypeObj, c.GetHeader("Accept-Language"))
+				c.AbortWithStatusJSON(status, res)
 			case error: // 原始的错误
 				if gin.IsDebugging() {
 					lv_err.PrintStackTrace(errTypeObj)
 				}
 				lv_log.Error(c, "CustomError XXXXXXXXXX: ", errTypeObj)
-				c.AbortWithStatusJSON(http.StatusOK, &lv_dto.CommonRes{Code: 500, Msg: cast.ToString(errTypeObj)})
+				status, res := lv_dto.ErrorResponse(errTypeObj, c.GetHeader("Accept-Language"))
+				c.AbortWithStatusJSON(status, res)
 			default:
 				lv_log.Error(c, "default CustomErrorXXXXXXXXXX: ", errTypeObj)
-				c.AbortWithStatusJSON(http.StatusOK, &lv_dto.CommonRes{Code: 500, Msg: "未知错误!"})
+				c.AbortWithStatusJSON(lv_dto.StatusOf(lv_dto.CodeError), &lv_dto.CommonRes{Code: 500, Msg: "未知错误!"})
 			}
 		} else {
 			lv_log.Info(c, "-----------request over----------")
@@ -58,3 +66,14 @@ func RecoverError(c *gin.Context) {
 	}()
 	c.Next()
 }
+
+// jsonCode 读取 json 字符串中的 code，lv_err.Assert1 等 panic 的是序列化后的 lv_dto.Resp
+func jsonCode(s string) int {
+	var res struct {
+		Code int `json:"code"`
+	}
+	if json.Unmarshal([]byte(s), &res) != nil {
+		return lv_dto.ERROR
+	}
+	return res.Code
+}
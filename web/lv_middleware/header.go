@@ -34,16 +34,19 @@ func NoCache(c *gin.Context) {
 	c.Next()
 }
 
-// Options is a lv_middleware function that appends headers
-// for options requests and aborts then exits the lv_middleware
-// chain and ends the request.
+// Options is a lv_middleware function that handles CORS by
+// application.cors, answers other options requests with the
+// allowed methods and ends the request.
 func Options(c *gin.Context) {
+	corsOnce.Do(func() {
+		corsHandler = corsWith(LoadCorsConfig())
+	})
+	if !corsHandler(c) {
+		return
+	}
 	if c.Request.Method != "OPTIONS" {
 		c.Next()
 	} else {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "authorization, origin, content-type, accept")
 		c.Header("Allow", "HEAD,GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		c.Header("Content-Type", "application/json")
 		c.AbortWithStatus(200)
@@ -51,9 +54,8 @@ func Options(c *gin.Context) {
 }
 
 // Secure is a lv_middleware function that appends security
-// and resource access headers.
+// and resource access headers, CORS headers are set by Options.
 func Secure(c *gin.Context) {
-	c.Header("Access-Control-Allow-Origin", "*")
 	//c.Header("X-Frame-Options", "DENY")
 	c.Header("X-Content-Type-Options", "nosniff")
 	c.Header("X-XSS-Protection", "1; mode=block")
@@ -0,0 +1,210 @@
+package lv_middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_cache"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/utils/lv_net"
+	"github.com/lostvip-com/lv_framework/web/lv_auth"
+	"github.com/lostvip-com/lv_framework/web/lv_dto"
+	"github.com/lostvip-com/lv_framework/web/lv_router"
+)
+
+// 限流维度，可逗号组合，例如 ip,permission
+const (
+	RateKeyIp         = "ip"         // 客户端ip
+	RateKeyUser       = "user"       // 登录用户，未登录时按ip
+	RateKeyPermission = "permission" // 路由的权限字符串，所有客户端共享额度
+)
+
+// RateLimitRoute 单个路由的限流规则，未配置的字段沿用全局配置
+// Path 为路由模板(不含 context-path)，以 * 结尾时按前缀匹配；Method 为空时匹配所有方法
+type RateLimitRoute struct {
+	Path                   string `mapstructure:"path"`
+	Method                 string `mapstructure:"method"`
+	Key                    string `mapstructure:"key"`
+	lv_cache.RateLimitRule `mapstructure:",squash"`
+}
+
+// RateLimitConfig 限流配置，全局规则 Limit 为0时只对 Routes 中的路由限流
+//
+//	application:
+//	  rate-limit:
+//	    enabled: true
+//	    key: ip
+//	    algorithm: token-bucket
+//	    limit: 100
+//	    window: 1m
+//	    routes:
+//	      - path: /login
+//	        method: POST
+//	        algorithm: sliding-window
+//	        limit: 5
+//	        window: 1m
+type RateLimitConfig struct {
+	Enabled                bool   `mapstructure:"enabled"`
+	Key                    string `mapstructure:"key"`
+	lv_cache.RateLimitRule `mapstructure:",squash"`
+	Routes                 []RateLimitRoute `mapstructure:"routes"`
+}
+
+// RateLimitUser 获取限流用的用户标识，返回空串时按ip限流
+// 限流在路由中间件之前执行，未经过 lv_auth.Middleware 时按 application.jwt 配置校验令牌
+var RateLimitUser = func(c *gin.Context) string {
+	if claims := lv_auth.GetClaims(c); claims != nil {
+		return claims.Username
+	}
+	token := lv_auth.GetToken(c)
+	if token == "" {
+		return ""
+	}
+	m, err := lv_auth.Default()
+	if err != nil {
+		return ""
+	}
+	claims, err := m.Parse(token)
+	if err != nil {
+		return ""
+	}
+	return claims.Username
+}
+
+// LoadRateLimitConfig 读取 application.rate-limit 配置
+func LoadRateLimitConfig() *RateLimitConfig {
+	cfg := new(RateLimitConfig)
+	cfg.Enabled = lv_conf.Config().GetBool(lv_global.KEY_RATE_LIMIT + ".enabled") //同时确保配置已加载
+	if err := lv_conf.Config().GetVipperCfg().UnmarshalKey(lv_global.KEY_RATE_LIMIT, cfg); err != nil {
+		lv_log.Error("rate-limit config error:", err)
+	}
+	return cfg
+}
+
+// RateLimit 按 application.rate-limit 配置限流
+func RateLimit() gin.HandlerFunc {
+	return RateLimitWith(LoadRateLimitConfig(), lv_cache.GetRateLimiter())
+}
+
+// RateLimitWith 使用指定的配置和限流实现，超出额度时返回429，配置错误时 panic
+// 响应头 RateLimit-Limit/RateLimit-Remaining/RateLimit-Reset 告知客户端剩余额度
+func RateLimitWith(cfg *RateLimitConfig, limiter lv_cache.RateLimiter) gin.HandlerFunc {
+	if err := cfg.Validate(); err != nil {
+		panic("rate-limit 配置错误: " + err.Error())
+	}
+	contextPath := strings.TrimSuffix(lv_conf.Config().GetContextPath(), "/")
+	return func(c *gin.Context) {
+		fullPath := c.FullPath()
+		if fullPath == "" { //未匹配到路由
+			c.Next()
+			return
+		}
+		path := strings.TrimPrefix(fullPath, contextPath)
+		name, keyType, rule := cfg.match(c.Request.Method, path)
+		if rule.Limit <= 0 || rule.Window <= 0 {
+			c.Next()
+			return
+		}
+		key := name + ":" + rateLimitKey(c, keyType, path)
+		res, err := limiter.Allow(c.Request.Context(), key, rule)
+		if err != nil { //限流存储不可用时放行，避免影响业务
+			lv_log.Error("rate limit error:", err)
+			c.Next()
+			return
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", seconds(res.Reset))
+		c.Header("RateLimit-Policy", strconv.Itoa(rule.Limit)+";w="+seconds(rule.Window))
+		if !res.Allowed {
+			c.Header("Retry-After", seconds(res.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, &lv_dto.CommonRes{Code: http.StatusTooManyRequests, Msg: "请求过于频繁，请稍后再试"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// match 查找路由对应的规则，返回规则名称(计数key的前缀)、限流维度和规则
+func (cfg *RateLimitConfig) match(method, path string) (string, string, lv_cache.RateLimitRule) {
+	for _, r := range cfg.Routes {
+		if r.Method != "" && !strings.EqualFold(r.Method, method) {
+			continue
+		}
+		if r.Path != path && !(strings.HasSuffix(r.Path, "*") && strings.HasPrefix(path, strings.TrimSuffix(r.Path, "*"))) {
+			continue
+		}
+		keyType := r.Key
+		if keyType == "" {
+			keyType = cfg.Key
+		}
+		return strings.ToUpper(r.Method) + r.Path, keyType, cfg.routeRule(r)
+	}
+	return "default", cfg.Key, cfg.RateLimitRule
+}
+
+// routeRule 路由规则中未配置的字段使用全局配置
+func (cfg *RateLimitConfig) routeRule(r RateLimitRoute) lv_cache.RateLimitRule {
+	rule := r.RateLimitRule
+	if rule.Algorithm == "" {
+		rule.Algorithm = cfg.Algorithm
+	}
+	if rule.Limit == 0 {
+		rule.Limit, rule.Burst = cfg.Limit, cfg.Burst
+	}
+	if rule.Window == 0 {
+		rule.Window = cfg.Window
+	}
+	return rule
+}
+
+// Validate 检查全局规则和每个路由合并后的规则
+func (cfg *RateLimitConfig) Validate() error {
+	if err := cfg.RateLimitRule.Validate(); err != nil {
+		return err
+	}
+	for _, r := range cfg.Routes {
+		if err := cfg.routeRule(r).Validate(); err != nil {
+			return fmt.Errorf("%s %s: %w", r.Method, r.Path, err)
+		}
+	}
+	return nil
+}
+
+func rateLimitKey(c *gin.Context, keyType, path string) string {
+	if keyType == "" {
+		keyType = RateKeyIp
+	}
+	parts := make([]string, 0, 2)
+	for _, k := range strings.Split(keyType, ",") {
+		switch strings.TrimSpace(k) {
+		case RateKeyUser:
+			if user := RateLimitUser(c); user != "" {
+				parts = append(parts, "u:"+user)
+			} else {
+				parts = append(parts, lv_net.GetRemoteClientIp(c.Request))
+			}
+		case RateKeyPermission:
+			if perm := lv_router.FindRoutePermission(c.Request.Method, path); perm != "" {
+				parts = append(parts, perm)
+			} else {
+				parts = append(parts, c.Request.Method+path)
+			}
+		default:
+			parts = append(parts, lv_net.GetRemoteClientIp(c.Request))
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// seconds 向上取整的秒数
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
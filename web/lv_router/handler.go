@@ -0,0 +1,194 @@
+package lv_router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/web/lv_dto"
+)
+
+type ginContextKey struct{}
+
+// Handle 将 func(ctx, req) (resp, error) 适配为 gin.HandlerFunc：
+// 按 uri/form/json 绑定请求参数并校验，resp 封装为 lv_dto.CommonRes 返回，error 交给 ErrorHandler 处理。
+// resp 本身实现了 lv_dto.R (如 lv_dto.RespPage) 时不再封装。注册路由时自动设置接口文档的请求、响应类型
+//
+//	group.POST("/:id", "system:user:edit", lv_router.Handle(userService.Save))
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) gin.HandlerFunc {
+	reqType := reflect.TypeFor[Req]()
+	respType := reflect.TypeFor[Resp]()
+	h := gin.HandlerFunc(func(c *gin.Context) {
+		var req Req
+		target := any(&req)
+		switch reqType.Kind() {
+		case reflect.Pointer: //Req 为指针时绑定到新建的对象
+			req = reflect.New(reqType.Elem()).Interface().(Req)
+			target = req
+		case reflect.Map:
+			req = reflect.MakeMap(reqType).Interface().(Req)
+		}
+		if err := Bind(c, target); err != nil {
+			ErrorHandler(c, err)
+			return
+		}
+		resp, err := fn(context.WithValue(c.Request.Context(), ginContextKey{}, c), req)
+		if err != nil {
+			ErrorHandler(c, err)
+			return
+		}
+		WriteResult(c, resp)
+	})
+	meta := &RouteMeta{}
+	if reqType.Kind() != reflect.Interface {
+		meta.Request = reflect.Zero(reqType).Interface()
+	}
+	if respType.Kind() != reflect.Interface {
+		meta.Response = reflect.Zero(respType).Interface()
+		meta.wrapped = !isEnvelope(respType)
+	}
+	typedHandlers.Store(handlerKey(h), meta)
+	return h
+}
+
+// GinContext 在 Handle 的处理函数中获取 gin.Context，例如读取上传的文件
+func GinContext(ctx context.Context) *gin.Context {
+	c, _ := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c
+}
+
+// Bind 依次绑定查询参数、请求体(json/表单)和路径参数，再按 validate/binding tag 校验
+// obj 为 map 时只有 map[string]string、map[string][]string 绑定查询参数、表单和路径参数，其它 map 只绑定json请求体
+func Bind(c *gin.Context, obj any) error {
+	if !bindable(obj) {
+		return nil
+	}
+	if v := reflect.ValueOf(obj).Elem(); v.Kind() == reflect.Map && v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	form := formBindable(obj)
+	if form {
+		if err := binding.MapFormWithTag(obj, c.Request.URL.Query(), "form"); err != nil {
+			return &ValidationError{Msg: "参数格式错误: " + err.Error()}
+		}
+	}
+	if err := bindBody(c, obj, form); err != nil {
+		return &ValidationError{Msg: "参数格式错误: " + err.Error()}
+	}
+	if form && len(c.Params) > 0 {
+		params := make(map[string][]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+			return &ValidationError{Msg: "参数格式错误: " + err.Error()}
+		}
+	}
+	return Validate(c, obj)
+}
+
+func bindBody(c *gin.Context, obj any, form bool) error {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	switch contentType := c.ContentType(); {
+	case contentType == binding.MIMEJSON:
+		err := json.NewDecoder(c.Request.Body).Decode(obj)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	case !form:
+		return nil
+	case contentType == binding.MIMEPOSTForm:
+		if err := c.Request.ParseForm(); err != nil {
+			return err
+		}
+		return binding.MapFormWithTag(obj, c.Request.PostForm, "form")
+	case contentType == binding.MIMEMultipartPOSTForm:
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+			return err
+		}
+		return binding.MapFormWithTag(obj, c.Request.MultipartForm.Value, "form")
+	}
+	return nil
+}
+
+// bindable 只绑定结构体和 map
+func bindable(obj any) bool {
+	t := reflect.TypeOf(obj)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return false
+	}
+	kind := t.Elem().Kind()
+	return kind == reflect.Struct || kind == reflect.Map
+}
+
+// formBindable 查询参数、表单只能绑定到结构体和 map[string]string、map[string][]string
+func formBindable(obj any) bool {
+	t := reflect.TypeOf(obj).Elem()
+	if t.Kind() != reflect.Map {
+		return true
+	}
+	return t.Key().Kind() == reflect.String &&
+		(t.Elem().Kind() == reflect.String || (t.Elem().Kind() == reflect.Slice && t.Elem().Elem().Kind() == reflect.String))
+}
+
+// ErrorHandler 将 Handle 中的错误写入响应，可替换为自定义的错误码映射
+// 默认：参数错误返回 lv_dto.CodeFail 和各字段的提示，lv_dto.BizError 按错误码返回，其它错误为 lv_dto.CodeError
+var ErrorHandler = func(c *gin.Context, err error) {
+	var verr *ValidationError
+	var biz *lv_dto.BizError
+	switch {
+	case errors.As(err, &verr):
+		err = lv_dto.CodeFail.New(verr.Msg).WithData(verr.Fields)
+	case !errors.As(err, &biz) || biz.Err != nil: //非预期的错误记录日志
+		lv_log.Error(c, "handler error:", err)
+	}
+	status, res := lv_dto.ErrorResponse(err, c.GetHeader("Accept-Language"))
+	c.AbortWithStatusJSON(status, res)
+}
+
+// WriteResult 写入成功的结果，已实现 lv_dto.R 的结果原样返回
+func WriteResult(c *gin.Context, resp any) {
+	if t := reflect.TypeOf(resp); t != nil && isEnvelope(t) {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.SUCCESS, Msg: "success", Data: resp})
+}
+
+var envelopeType = reflect.TypeFor[lv_dto.R]()
+
+func isEnvelope(t reflect.Type) bool {
+	return t.Implements(envelopeType) || (t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(envelopeType))
+}
+
+// 由 Handle 生成的处理函数及其文档信息，以闭包地址区分
+var typedHandlers sync.Map
+
+func handlerKey(h gin.HandlerFunc) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&h))
+}
+
+// typedMeta 路由的处理函数中有 Handle 生成的函数时，返回其文档信息
+func typedMeta(handlers []gin.HandlerFunc) *RouteMeta {
+	for i := len(handlers) - 1; i >= 0; i-- {
+		if handlers[i] == nil {
+			continue
+		}
+		if meta, ok := typedHandlers.Load(handlerKey(handlers[i])); ok {
+			m := *meta.(*RouteMeta)
+			return &m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package lv_router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/web/lv_dto"
+)
+
+type saveReq struct {
+	Id    int64  `uri:"id"`
+	Dept  string `form:"dept"`
+	Name  string `json:"name" label:"名称" validate:"required,min=2"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type codeError struct{}
+
+func (codeError) Error() string { return "用户不存在" }
+func (codeError) GetCode() int  { return 404 }
+
+func TestHandle(t *testing.T) {
+	GroupList = nil
+	gin.SetMode(gin.TestMode)
+	group := New("/user").POST("/:id", "", Handle(func(ctx context.Context, req *saveReq) (*saveReq, error) {
+		if GinContext(ctx) == nil {
+			return nil, errors.New("no gin context")
+		}
+		if req.Id == 0 {
+			return nil, codeError{}
+		}
+		return req, nil
+	}))
+	r := gin.New()
+	for _, route := range group.Router {
+		r.Handle(route.Method, group.RelativePath+route.RelativePath, route.HandlerFunc...)
+	}
+	if meta := group.Router[0].Meta; meta == nil || !meta.wrapped || meta.Request.(*saveReq) != nil {
+		t.Fatal(meta)
+	}
+	op := BuildOpenAPI(OpenAPIInfo{}).Paths["/user/{id}"]["post"]
+	if op.RequestBody == nil || op.Responses["200"].Content["application/json"].Schema.Properties["data"].Ref != "#/components/schemas/saveReq" {
+		t.Fatal(op)
+	}
+
+	cases := []struct {
+		path, body, lang string
+		code             int
+		msg              string
+	}{
+		{"/user/7?dept=it", `{"name":"admin"}`, "", lv_dto.SUCCESS, "success"},
+		{"/user/7", `{"name":"a"}`, "zh-CN,zh;q=0.9", lv_dto.FAIL, "名称长度必须至少为2个字符"},
+		{"/user/7", `{}`, "en-US", lv_dto.FAIL, "名称 is a required field"},
+		{"/user/7", `{"name":"admin","email":"x"}`, "", lv_dto.FAIL, "email必须是一个有效的邮箱"},
+		{"/user/7", `{"name":1}`, "", lv_dto.FAIL, "参数格式错误"},
+		{"/user/0", `{"name":"admin"}`, "", 404, "用户不存在"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", tc.lang)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var res struct {
+			lv_dto.CommonRes
+			Data *saveReq `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		if w.Code != http.StatusOK || res.Code != tc.code || !strings.HasPrefix(res.Msg, tc.msg) {
+			t.Fatal(tc.path, tc.body, w.Body.String())
+		}
+		if tc.code == lv_dto.SUCCESS && (res.Data.Id != 7 || res.Data.Dept != "it" || res.Data.Name != "admin") {
+			t.Fatal(w.Body.String())
+		}
+	}
+}
+
+func TestHandleMap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/any", Handle(func(ctx context.Context, req map[string]any) (map[string]any, error) {
+		return req, nil
+	}))
+	r.POST("/str/:id", Handle(func(ctx context.Context, req map[string]string) (map[string]string, error) {
+		return req, nil
+	}))
+	cases := []struct {
+		path, body, want string
+	}{
+		{"/any", ``, `{}`},
+		{"/any?x=1", `{"name":"admin"}`, `{"name":"admin"}`}, //只绑定json
+		{"/str/7?dept=it", ``, `{"dept":"it","id":"7"}`},
+		{"/str/7?dept=it", `{"name":"admin"}`, `{"dept":"it","id":"7","name":"admin"}`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var res struct {
+			lv_dto.CommonRes
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		if res.Code != lv_dto.SUCCESS || string(res.Data) != tc.want {
+			t.Fatal(tc.path, tc.body, w.Body.String())
+		}
+	}
+}
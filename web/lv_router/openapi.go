@@ -0,0 +1,243 @@
+package lv_router
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIInfo 文档标题、版本，ServerUrl 一般为 context-path
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	ServerUrl   string `json:"-"`
+}
+
+type OpenAPIDocument struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       OpenAPIInfo                      `json:"info"`
+	Servers    []map[string]string              `json:"servers,omitempty"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]map[string]any `json:"securitySchemes,omitempty"`
+}
+
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Permission  string                `json:"x-permission,omitempty"` // lv_router 注册的权限字符串
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // path/query/header
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+var ginParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// BuildOpenAPI 由 GroupList 中的路由生成 OpenAPI 3.1 文档
+func BuildOpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{OpenAPI: "3.1.0", Info: info, Paths: make(map[string]map[string]*Operation)}
+	if info.ServerUrl != "" {
+		doc.Servers = []map[string]string{{"url": info.ServerUrl}}
+	}
+	b := newSchemaBuilder()
+	secured := false
+	for _, group := range GroupList {
+		for _, r := range group.Router {
+			fullPath := joinPath(group.RelativePath, r.RelativePath)
+			apiPath := ginParam.ReplaceAllString(fullPath, "{$1}")
+			methods := []string{r.Method}
+			if r.Method == "ANY" {
+				methods = []string{GET, POST, PUT, PATCH, DELETE}
+			}
+			for _, method := range methods {
+				op := buildOperation(b, method, fullPath, group.RelativePath, r)
+				if op.Permission != "" {
+					op.Security = []map[string][]string{{"bearerAuth": {}}}
+					secured = true
+				}
+				if doc.Paths[apiPath] == nil {
+					doc.Paths[apiPath] = make(map[string]*Operation)
+				}
+				doc.Paths[apiPath][strings.ToLower(method)] = op
+			}
+		}
+	}
+	doc.Components.Schemas = b.schemas
+	if secured {
+		doc.Components.SecuritySchemes = map[string]map[string]any{
+			"bearerAuth": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+		}
+	}
+	return doc
+}
+
+func buildOperation(b *schemaBuilder, method, fullPath, groupPath string, r *router) *Operation {
+	op := &Operation{
+		OperationId: operationId(method, fullPath),
+		Permission:  r.Permiss,
+		Responses:   map[string]*Response{"200": {Description: "OK"}},
+	}
+	meta := r.Meta
+	if meta == nil {
+		meta = &RouteMeta{}
+	}
+	op.Summary, op.Description, op.Deprecated, op.Tags = meta.Summary, meta.Description, meta.Deprecated, meta.Tags
+	if len(op.Tags) == 0 {
+		if tag := strings.Trim(groupPath, "/"); tag != "" {
+			op.Tags = []string{tag}
+		}
+	}
+	for _, name := range ginParam.FindAllStringSubmatch(fullPath, -1) { //路径参数都是必填的
+		op.Parameters = append(op.Parameters, &Parameter{Name: name[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if meta.Request != nil {
+		applyRequest(b, op, method, reflect.TypeOf(meta.Request))
+	}
+	if meta.Response != nil {
+		schema := b.schema(reflect.TypeOf(meta.Response))
+		if meta.wrapped {
+			schema = &Schema{Type: "object", Properties: map[string]*Schema{
+				"code": {Type: "integer", Format: "int32"}, "msg": {Type: "string"}, "data": schema,
+			}}
+		}
+		op.Responses["200"].Content = map[string]*MediaType{"application/json": {Schema: schema}}
+	}
+	return op
+}
+
+// applyRequest uri 标记的字段为路径参数，header 为请求头；
+// GET/DELETE 请求 form 标记的字段为查询参数，其它请求 json 字段为 JSON 请求体、form 字段为表单请求体
+func applyRequest(b *schemaBuilder, op *Operation, method string, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: b.schema(t)}}}
+		return
+	}
+	params := make(map[string]*Parameter)
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+	addParam := func(in, tag string) {
+		b.fields(t, tag, func(name string, f reflect.StructField) {
+			s := b.schema(f.Type)
+			required := applyValidate(s, f)
+			if p, ok := params[name]; ok && in == "path" {
+				p.Schema = s
+				return
+			}
+			p := &Parameter{Name: name, In: in, Required: required || in == "path", Schema: s}
+			params[name] = p
+			op.Parameters = append(op.Parameters, p)
+		})
+	}
+	addParam("path", "uri")
+	addParam("header", "header")
+	hasBody := method != GET && method != DELETE && method != HEAD
+	if !hasBody {
+		addParam("query", "form")
+		return
+	}
+	content := make(map[string]*MediaType)
+	if hasTag(t, "json") {
+		content["application/json"] = &MediaType{Schema: b.schema(t)}
+	}
+	if hasTag(t, "form") {
+		form := b.structSchema(t, "form")
+		content["application/x-www-form-urlencoded"] = &MediaType{Schema: form}
+		content["multipart/form-data"] = &MediaType{Schema: form}
+	}
+	if len(content) > 0 {
+		op.RequestBody = &RequestBody{Required: true, Content: content}
+	}
+}
+
+func hasTag(t reflect.Type, tag string) bool {
+	found := false
+	b := newSchemaBuilder()
+	b.fields(t, tag, func(name string, f reflect.StructField) {
+		if _, ok := f.Tag.Lookup(tag); ok {
+			found = true
+		}
+	})
+	return found
+}
+
+// operationId 由方法和路径生成，例如 GET /system/user/:id -> get_system_user_id
+func operationId(method, fullPath string) string {
+	parts := strings.FieldsFunc(fullPath, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	return strings.ToLower(method) + "_" + strings.Join(parts, "_")
+}
+
+// OpenAPIHandler 返回 OpenAPI 文档，第一次请求时生成，此时所有路由都已注册
+func OpenAPIHandler(info OpenAPIInfo) gin.HandlerFunc {
+	var once sync.Once
+	var doc *OpenAPIDocument
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc = BuildOpenAPI(info)
+		})
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// SwaggerUIHandler Swagger UI 页面，docUrl 为文档地址，cdn 为 swagger-ui-dist 的地址，内网部署时可改为本地静态资源
+func SwaggerUIHandler(docUrl, cdn string) gin.HandlerFunc {
+	if cdn == "" {
+		cdn = "https://unpkg.com/swagger-ui-dist@5"
+	}
+	cdn = strings.TrimSuffix(cdn, "/")
+	page := `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8"/>
+<title>API</title>
+<link rel="stylesheet" href="` + cdn + `/swagger-ui.css"/>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="` + cdn + `/swagger-ui-bundle.js"></script>
+<script>window.ui = SwaggerUIBundle({url: "` + docUrl + `", dom_id: "#swagger-ui"});</script>
+</body>
+</html>`
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	}
+}
@@ -0,0 +1,87 @@
+package lv_router
+
+import (
+	"testing"
+	"time"
+)
+
+type userQuery struct {
+	Id      int64  `uri:"id" validate:"required"`
+	Trace   string `header:"X-Trace"`
+	Expand  string `form:"expand" validate:"oneof=dept roles"`
+	Verbose bool   `form:"verbose"`
+}
+
+type userSave struct {
+	Id       int64    `uri:"id"`
+	Name     string   `json:"name" validate:"required,min=2,max=20"`
+	Email    string   `json:"email" validate:"omitempty,email"`
+	Age      int      `json:"age" validate:"gte=0,lte=150"`
+	Roles    []string `json:"roles" validate:"max=5"`
+	Password string   `json:"-"`
+}
+
+type userResp struct {
+	Id      int64     `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Leader  *userResp `json:"leader,omitempty"`
+	Tags    []userTag `json:"tags"`
+	Attrs   map[string]int
+}
+
+type userTag struct {
+	Name string `json:"name"`
+}
+
+func TestBuildOpenAPI(t *testing.T) {
+	GroupList = nil
+	New("/system/user").
+		GET("/:id", "system:user:view", nil).Doc(RouteMeta{Summary: "用户详情", Request: userQuery{}, Response: userResp{}}).
+		POST("/:id", "system:user:edit", nil).Doc(RouteMeta{Request: &userSave{}}).
+		GET("/ping", "", nil)
+	doc := BuildOpenAPI(OpenAPIInfo{Title: "test", Version: "1"})
+
+	get := doc.Paths["/system/user/{id}"]["get"]
+	if get == nil || get.Summary != "用户详情" || get.Permission != "system:user:view" || len(get.Security) != 1 {
+		t.Fatal(get)
+	}
+	params := map[string]*Parameter{}
+	for _, p := range get.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	if p := params["path:id"]; p == nil || !p.Required || p.Schema.Format != "int64" {
+		t.Fatal(get.Parameters)
+	}
+	if p := params["query:expand"]; p == nil || len(p.Schema.Enum) != 2 {
+		t.Fatal(get.Parameters)
+	}
+	if params["header:X-Trace"] == nil || params["query:verbose"] == nil || len(get.Parameters) != 4 {
+		t.Fatal(get.Parameters)
+	}
+	if ref := get.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/userResp" {
+		t.Fatal(ref)
+	}
+	resp := doc.Components.Schemas["userResp"]
+	if resp.Properties["leader"].Ref != "#/components/schemas/userResp" || resp.Properties["created"].Format != "date-time" ||
+		resp.Properties["Attrs"].AdditionalProperties.Type != "integer" || resp.Properties["tags"].Items.Ref == "" {
+		t.Fatal(resp.Properties)
+	}
+
+	post := doc.Paths["/system/user/{id}"]["post"]
+	save := doc.Components.Schemas["userSave"]
+	if post.RequestBody == nil || post.RequestBody.Content["application/json"] == nil || save == nil {
+		t.Fatal(post.RequestBody)
+	}
+	name := save.Properties["name"]
+	if *name.MinLength != 2 || *name.MaxLength != 20 || len(save.Required) != 1 || save.Required[0] != "name" {
+		t.Fatal(save)
+	}
+	if save.Properties["email"].Format != "email" || *save.Properties["age"].Maximum != 150 ||
+		*save.Properties["roles"].MaxItems != 5 || save.Properties["Password"] != nil {
+		t.Fatal(save.Properties)
+	}
+	if op := doc.Paths["/system/user/ping"]["get"]; op == nil || op.Security != nil || op.OperationId != "get_system_user_ping" {
+		t.Fatal(op)
+	}
+}
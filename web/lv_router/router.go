@@ -17,8 +17,10 @@
 package lv_router
 
 import (
-	"github.com/gin-gonic/gin"
+	"path"
 	"strings"
+
+	"github.com/gin-gonic/gin"
 )
 
 const (
@@ -39,12 +41,27 @@ var GroupList = make([]*routerGroup, 0)
 // 注册所有的URL标识
 var PermissionMap = make(map[string]string, 0)
 
+// 按 方法+路由模板 注册的权限字符串，路由模板与 gin 的 FullPath 一致(不含 context-path)
+var routePermissions = make(map[string]string, 0)
+
 // 路由信息
 type router struct {
 	Method       string            //方法名称
 	RelativePath string            //url路径
 	Permiss      string            //权限字符串
 	HandlerFunc  []gin.HandlerFunc //执行函数
+	Meta         *RouteMeta        //接口文档信息
+}
+
+// RouteMeta 接口文档信息，Request/Response 传结构体的零值，用于生成 OpenAPI 文档
+type RouteMeta struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Request     any
+	Response    any
+	Deprecated  bool
+	wrapped     bool //Response 由 Handle 封装在 lv_dto.CommonRes 的 data 中
 }
 
 // 路由组信息
@@ -80,6 +97,27 @@ func FindPermission(url string) string {
 	return PermissionMap[url]
 }
 
+// FindRoutePermission 根据请求方法和路由模板获取权限字符串，fullPath 为 gin.Context.FullPath 去掉 context-path
+// 带参数的路由 /user/:id 也能匹配
+func FindRoutePermission(method, fullPath string) string {
+	if permiss, ok := routePermissions[method+" "+fullPath]; ok {
+		return permiss
+	}
+	return routePermissions["ANY "+fullPath]
+}
+
+// joinPath 与 gin 拼接路由的规则一致，保留结尾的 /
+func joinPath(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
 // 创建一个路由组
 func New(relativePath string, middleware ...gin.HandlerFunc) *routerGroup {
 	var rg routerGroup
@@ -97,6 +135,7 @@ func (group *routerGroup) Handle(method, relativePath, permiss string, handlers
 	r.Permiss = permiss
 	r.RelativePath = relativePath
 	r.HandlerFunc = handlers
+	r.Meta = typedMeta(handlers)
 	group.Router = append(group.Router, &r)
 	if len(permiss) > 0 {
 		if strings.EqualFold(relativePath, "/") {
@@ -104,6 +143,26 @@ func (group *routerGroup) Handle(method, relativePath, permiss string, handlers
 		} else {
 			PermissionMap[group.RelativePath+relativePath] = permiss
 		}
+		routePermissions[method+" "+joinPath(group.RelativePath, relativePath)] = permiss
+	}
+	return group
+}
+
+// Doc 为最后添加的路由设置接口文档信息，Request/Response 为空时保留 Handle 自动设置的类型
+//
+//	group.GET("/:id", "system:user:view", handler).Doc(lv_router.RouteMeta{Summary: "用户详情", Request: UserReq{}, Response: UserResp{}})
+func (group *routerGroup) Doc(meta RouteMeta) *routerGroup {
+	if len(group.Router) > 0 {
+		r := group.Router[len(group.Router)-1]
+		if r.Meta != nil {
+			if meta.Request == nil {
+				meta.Request = r.Meta.Request
+			}
+			if meta.Response == nil {
+				meta.Response, meta.wrapped = r.Meta.Response, r.Meta.wrapped
+			}
+		}
+		r.Meta = &meta
 	}
 	return group
 }
@@ -0,0 +1,249 @@
+package lv_router
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema OpenAPI 3.1 的 JSON Schema，只包含生成文档用到的字段
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaBuilder 生成 schema，命名的结构体放到 components 中并使用 $ref 引用
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{schemas: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t, "json")
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.define(t)}
+	}
+	return &Schema{}
+}
+
+// define 将结构体登记到 components，不同包的同名结构体加上包名区分
+func (b *schemaBuilder) define(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, exists := b.schemas[name]; exists {
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + name
+	}
+	b.names[t] = name
+	b.schemas[name] = &Schema{} //先占位，支持自引用
+	*b.schemas[name] = *b.structSchema(t, "json")
+	return name
+}
+
+// structSchema 按 tag 指定的名称生成对象，tag 为空时与 encoding/json 一样使用字段名
+func (b *schemaBuilder) structSchema(t reflect.Type, tag string) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.fields(t, tag, func(name string, f reflect.StructField) {
+		prop := b.schema(f.Type)
+		required := applyValidate(prop, f)
+		if desc := f.Tag.Get("description"); desc != "" && prop.Ref == "" {
+			prop.Description = desc
+		}
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	})
+	return s
+}
+
+// fields 遍历导出字段，嵌入的结构体与 encoding/json 一样展开
+func (b *schemaBuilder) fields(t reflect.Type, tag string, fn func(name string, f reflect.StructField)) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f, tag)
+		if f.Anonymous && !ok {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(ft, tag, fn)
+				continue
+			}
+		}
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			if tag != "json" {
+				continue
+			}
+			name = f.Name
+		}
+		fn(name, f)
+	}
+}
+
+// fieldName 返回tag中的名称，ok 表示tag存在
+func fieldName(f reflect.StructField, tag string) (string, bool) {
+	v, ok := f.Tag.Lookup(tag)
+	if !ok {
+		return "", false
+	}
+	return strings.Split(v, ",")[0], true
+}
+
+// applyValidate 将 validate/binding 规则转为 schema 约束，返回是否必填
+func applyValidate(s *Schema, f reflect.StructField) bool {
+	rules := f.Tag.Get("validate")
+	if rules == "" {
+		rules = f.Tag.Get("binding")
+	}
+	required := false
+	kind := s.Type
+	for _, rule := range strings.Split(rules, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "ip", "ipv4":
+			s.Format = "ipv4"
+		case "ipv6":
+			s.Format = "ipv6"
+		case "datetime":
+			s.Format = "date-time"
+		case "oneof":
+			for _, v := range strings.Fields(value) {
+				s.Enum = append(s.Enum, enumValue(kind, v))
+			}
+		case "min", "gte":
+			setBound(s, kind, value, true, false)
+		case "max", "lte":
+			setBound(s, kind, value, false, false)
+		case "gt":
+			setBound(s, kind, value, true, true)
+		case "lt":
+			setBound(s, kind, value, false, true)
+		case "len":
+			setBound(s, kind, value, true, false)
+			setBound(s, kind, value, false, false)
+		}
+	}
+	return required
+}
+
+// setBound 字符串为长度，数组为元素个数，数值为取值范围
+func setBound(s *Schema, kind, value string, lower, exclusive bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	switch kind {
+	case "string", "array":
+		i := int(n)
+		if exclusive {
+			if lower {
+				i++
+			} else {
+				i--
+			}
+		}
+		switch {
+		case kind == "string" && lower:
+			s.MinLength = &i
+		case kind == "string":
+			s.MaxLength = &i
+		case lower:
+			s.MinItems = &i
+		default:
+			s.MaxItems = &i
+		}
+	case "integer", "number":
+		switch {
+		case lower && exclusive:
+			s.ExclusiveMinimum = &n
+		case lower:
+			s.Minimum = &n
+		case exclusive:
+			s.ExclusiveMaximum = &n
+		default:
+			s.Maximum = &n
+		}
+	}
+}
+
+func enumValue(kind, v string) any {
+	switch kind {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
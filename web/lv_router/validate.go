@@ -0,0 +1,119 @@
+package lv_router
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_trans "github.com/go-playground/validator/v10/translations/en"
+	zh_trans "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// DefaultLang 请求头 Accept-Language 不是支持的语言(zh/en)时，校验提示使用的语言
+var DefaultLang = "zh"
+
+// ValidationError 参数绑定或校验失败，Fields 为 字段名->提示信息
+type ValidationError struct {
+	Msg    string
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+// tagValidator 翻译的文本登记在 Translator 中，每个 Validate 需要各自的 Translator
+type tagValidator struct {
+	validate    *validator.Validate
+	translators *ut.UniversalTranslator
+}
+
+var (
+	validateOnce sync.Once
+	validators   []tagValidator //分别校验 validate 和 binding 两种tag
+)
+
+func initValidators() {
+	for _, tag := range []string{"validate", "binding"} {
+		v := validator.New(validator.WithRequiredStructEnabled())
+		v.SetTagName(tag)
+		v.RegisterTagNameFunc(labelName)
+		uni := ut.New(zh.New(), zh.New(), en.New())
+		zhTrans, _ := uni.GetTranslator("zh")
+		enTrans, _ := uni.GetTranslator("en")
+		_ = zh_trans.RegisterDefaultTranslations(v, zhTrans)
+		_ = en_trans.RegisterDefaultTranslations(v, enTrans)
+		validators = append(validators, tagValidator{validate: v, translators: uni})
+	}
+}
+
+// labelName 提示信息中的字段名，优先使用 label tag，其次为 json/form/uri 中的名称
+func labelName(f reflect.StructField) string {
+	for _, tag := range []string{"label", "json", "form", "uri"} {
+		if name, ok := fieldName(f, tag); ok && name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// Validate 按 validate/binding tag 校验结构体，提示信息的语言取自请求头 Accept-Language
+func Validate(c *gin.Context, obj any) error {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	validateOnce.Do(initValidators)
+	langs := acceptLanguages(c)
+	var verr *ValidationError
+	for _, v := range validators {
+		err := v.validate.Struct(obj)
+		errs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if verr == nil {
+			verr = &ValidationError{Fields: make(map[string]string)}
+		}
+		trans, _ := v.translators.FindTranslator(langs...)
+		for _, fe := range errs {
+			msg := fe.Translate(trans)
+			if _, exists := verr.Fields[fe.Field()]; !exists {
+				verr.Fields[fe.Field()] = msg
+			}
+			if verr.Msg == "" {
+				verr.Msg = msg
+			}
+		}
+	}
+	if verr != nil {
+		return verr
+	}
+	return nil
+}
+
+// acceptLanguages 解析 Accept-Language，如 zh-CN,zh;q=0.9,en;q=0.8 -> zh, en, 最后追加 DefaultLang
+func acceptLanguages(c *gin.Context) []string {
+	var langs []string
+	if c != nil {
+		for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+			lang, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+			lang, _, _ = strings.Cut(strings.ToLower(lang), "-")
+			if lang != "" {
+				langs = append(langs, lang)
+			}
+		}
+	}
+	return append(langs, DefaultLang)
+}
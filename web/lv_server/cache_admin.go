@@ -0,0 +1,186 @@
+package lv_server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_cache"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/web/lv_auth"
+	"github.com/lostvip-com/lv_framework/web/lv_dto"
+	"github.com/lostvip-com/lv_framework/web/lv_router"
+	"github.com/spf13/cast"
+)
+
+// CacheKeyInfo 缓存key的详情，Value 按类型分别为 string、map、[]string
+type CacheKeyInfo struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	TTL   int64       `json:"ttl"` //剩余秒数，-1 永不过期
+	Value interface{} `json:"value"`
+}
+
+// RegisterCacheAdmin 注册缓存管理接口，查看类接口使用 permission+":view"，删除接口使用 permission+":remove"
+// middleware 为认证和权限校验中间件，例如 lv_auth.Middleware()、lv_auth.RBAC()，为空时拒绝注册
+//
+//	GET  {path}/stats                         按key前缀的命中统计
+//	GET  {path}/keys?pattern=user:*&cursor=   按匹配规则游标分页列出key，返回下一页的 cursor，为空表示已到最后
+//	GET  {path}/key?key=user:1                查看key的类型、值和剩余时间
+//	POST {path}/remove  key=a&key=b 或 pattern=user:*
+func RegisterCacheAdmin(path, permission string, middleware ...gin.HandlerFunc) error {
+	if len(middleware) == 0 {
+		return errors.New("cache admin requires auth middleware")
+	}
+	view, remove := permission+":view", permission+":remove"
+	group := lv_router.New(path, middleware...)
+	group.GET("/stats", view, cacheStats)
+	group.GET("/keys", view, cacheKeys)
+	group.GET("/key", view, cacheKey)
+	group.POST("/remove", remove, cacheRemove)
+	return nil
+}
+
+// registerCacheAdminFromConf 按 application.cache.admin 配置注册缓存管理接口
+// 使用 application.jwt 校验令牌，并按令牌中的权限校验 permission，未配置jwt时不注册
+//
+//	application:
+//	  cache:
+//	    admin:
+//	      enabled: true
+//	      path: /monitor/cache
+//	      permission: monitor:cache
+func registerCacheAdminFromConf() {
+	if !lv_conf.Config().GetBool(lv_global.KEY_CACHE_ADMIN + ".enabled") {
+		return
+	}
+	path := lv_conf.Config().GetValueStr(lv_global.KEY_CACHE_ADMIN + ".path")
+	if path == "" {
+		path = "/monitor/cache"
+	}
+	permission := lv_conf.Config().GetValueStr(lv_global.KEY_CACHE_ADMIN + ".permission")
+	if permission == "" {
+		permission = "monitor:cache"
+	}
+	m, err := lv_auth.Default()
+	if err != nil {
+		lv_log.Error("cache admin not registered, jwt config error:", err)
+		return
+	}
+	if err = RegisterCacheAdmin(path, permission, m.Middleware(), lv_auth.RBAC()); err != nil {
+		lv_log.Error("cache admin not registered:", err)
+	}
+}
+
+func cacheStats(c *gin.Context) {
+	stats := lv_cache.Metrics().Stats()
+	rows := make([]gin.H, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, gin.H{"prefix": s.Prefix, "calls": s.Calls, "hits": s.Hits, "misses": s.Misses,
+			"errors": s.Errors, "hitRate": s.HitRate(), "avgLatencyMs": avgMillis(s.Latency, s.Calls),
+			"maxLatencyMs": float64(s.MaxLatency) / float64(time.Millisecond)})
+	}
+	c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.SUCCESS, Msg: "success", Data: rows})
+}
+
+func avgMillis(total time.Duration, calls int64) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return float64(total) / float64(calls) / float64(time.Millisecond)
+}
+
+func cacheKeys(c *gin.Context) {
+	pattern := c.DefaultQuery("pattern", "*")
+	count := cast.ToInt(c.Query("count"))
+	if count <= 0 || count > 1000 {
+		count = 100
+	}
+	keys, next, err := lv_cache.GetCacheClient().ScanPage(c.Request.Context(), pattern, c.Query("cursor"), count)
+	if err != nil {
+		c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.ERROR, Msg: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.SUCCESS, Msg: "success", Data: gin.H{"keys": keys, "cursor": next}})
+}
+
+func cacheKey(c *gin.Context) {
+	key := c.Query("key")
+	if key == "" {
+		c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.FAIL, Msg: "key不能为空"})
+		return
+	}
+	info, err := inspectKey(lv_cache.GetCacheClient(), key)
+	if err != nil {
+		c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.ERROR, Msg: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.SUCCESS, Msg: "success", Data: info})
+}
+
+// inspectKey 按key的类型读取内容
+func inspectKey(cache lv_cache.ICache, key string) (*CacheKeyInfo, error) {
+	typ, err := cache.Type(key)
+	if err != nil {
+		return nil, err
+	}
+	if typ == "none" {
+		return nil, errors.New(key + "不存在")
+	}
+	ttl, err := cache.TTL(key)
+	if err != nil {
+		return nil, err
+	}
+	info := &CacheKeyInfo{Key: key, Type: typ, TTL: int64(ttl / time.Second)}
+	if ttl < 0 {
+		info.TTL = -1
+	}
+	switch typ {
+	case "string":
+		info.Value, err = cache.Get(key)
+	case "hash":
+		info.Value, err = cache.HGetAll(key)
+	case "list":
+		info.Value, err = cache.LRange(key, 0, -1)
+	case "set":
+		info.Value, err = cache.SMembers(key)
+	case "zset":
+		info.Value, err = cache.ZRangeByScore(key, "-inf", "+inf")
+	default:
+		return nil, errors.New(key + "类型不支持: " + typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func cacheRemove(c *gin.Context) {
+	cache := lv_cache.GetCacheClient()
+	var count int64
+	if pattern := c.PostForm("pattern"); pattern != "" {
+		n, err := cache.DelByPattern(c.Request.Context(), pattern)
+		if err != nil {
+			c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.ERROR, Msg: err.Error()})
+			return
+		}
+		count = n
+	}
+	if keys := c.PostFormArray("key"); len(keys) > 0 {
+		for _, key := range keys {
+			n, err := cache.Exists(key) //Del 不返回删除的数量，只统计存在的key
+			if err == nil && n > 0 {
+				err = cache.Del(key) //逐个删除，集群模式下不会跨slot
+			}
+			if err != nil {
+				c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.ERROR, Msg: err.Error()})
+				return
+			}
+			count += n
+		}
+	}
+	c.JSON(http.StatusOK, &lv_dto.CommonRes{Code: lv_dto.SUCCESS, Msg: "success", Data: count})
+}
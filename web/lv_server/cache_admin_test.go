@@ -0,0 +1,28 @@
+package lv_server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+)
+
+func TestInspectKey(t *testing.T) {
+	c := lv_ram.NewRamCacheClient()
+	c.Set("str", "v", time.Minute)
+	c.HSet("hash", "a", "1")
+	c.RPush("list", "x", "y")
+	for key, want := range map[string]*CacheKeyInfo{
+		"str":  {Key: "str", Type: "string", TTL: 60, Value: "v"},
+		"hash": {Key: "hash", Type: "hash", TTL: -1, Value: map[string]string{"a": "1"}},
+		"list": {Key: "list", Type: "list", TTL: -1, Value: []string{"x", "y"}},
+	} {
+		if info, err := inspectKey(c, key); err != nil || !reflect.DeepEqual(info, want) {
+			t.Fatal(key, info, err)
+		}
+	}
+	if _, err := inspectKey(c, "missing"); err == nil {
+		t.Fatal("missing key")
+	}
+}
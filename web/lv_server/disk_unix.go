@@ -0,0 +1,14 @@
+//go:build !windows
+
+package lv_server
+
+import "syscall"
+
+// diskUsage 磁盘可用空间和总空间，单位字节
+func diskUsage(path string) (free, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err = syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), uint64(st.Blocks) * uint64(st.Bsize), nil
+}
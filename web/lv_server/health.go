@@ -0,0 +1,266 @@
+package lv_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_cache"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_db"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+// Health 健康检查结果，Components 为各组件的检查结果
+type Health struct {
+	Status     string             `json:"status"`
+	Details    map[string]any     `json:"details,omitempty"`
+	Components map[string]*Health `json:"components,omitempty"`
+}
+
+// HealthChecker 组件健康检查，返回的 details 会显示在结果中，返回 error 时组件为 DOWN
+type HealthChecker interface {
+	Check(ctx context.Context) (map[string]any, error)
+}
+
+// HealthCheckerFunc 函数形式的 HealthChecker
+type HealthCheckerFunc func(ctx context.Context) (map[string]any, error)
+
+func (f HealthCheckerFunc) Check(ctx context.Context) (map[string]any, error) {
+	return f(ctx)
+}
+
+var (
+	healthLock     sync.RWMutex
+	healthCheckers = make(map[string]HealthChecker)
+)
+
+// RegisterHealthChecker 注册健康检查，同名的会被覆盖，就绪检查和 /actuator/health 会执行所有的检查
+func RegisterHealthChecker(name string, checker HealthChecker) {
+	healthLock.Lock()
+	defer healthLock.Unlock()
+	healthCheckers[name] = checker
+}
+
+// CheckHealth 并发执行所有的检查，每个检查最多等待 timeout，有一个为 DOWN 时整体为 DOWN
+func CheckHealth(ctx context.Context, timeout time.Duration) *Health {
+	healthLock.RLock()
+	checkers := make(map[string]HealthChecker, len(healthCheckers))
+	for name, checker := range healthCheckers {
+		checkers[name] = checker
+	}
+	healthLock.RUnlock()
+
+	result := &Health{Status: StatusUp, Components: make(map[string]*Health, len(checkers))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := runCheck(ctx, timeout, checker)
+			mu.Lock()
+			defer mu.Unlock()
+			result.Components[name] = h
+			if h.Status != StatusUp {
+				result.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+func runCheck(ctx context.Context, timeout time.Duration, checker HealthChecker) *Health {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type checkResult struct {
+		details map[string]any
+		err     error
+	}
+	done := make(chan checkResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- checkResult{err: fmt.Errorf("%v", r)}
+			}
+		}()
+		details, err := checker.Check(ctx)
+		done <- checkResult{details, err}
+	}()
+	var r checkResult
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = ctx.Err()
+	}
+	h := &Health{Status: StatusUp, Details: r.details}
+	if r.err != nil {
+		h.Status = StatusDown
+		if h.Details == nil {
+			h.Details = make(map[string]any)
+		}
+		h.Details["error"] = r.err.Error()
+	}
+	return h
+}
+
+// DatabaseHealthChecker 检查所有配置的数据源，未初始化的会先创建连接，创建失败或没有数据源时为 DOWN
+func DatabaseHealthChecker() HealthChecker {
+	return HealthCheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		details := make(map[string]any)
+		engine := lv_db.GetInstance()
+		dbs := engine.GetAllDB()
+		nameSet := make(map[string]bool)
+		if cfg := lv_conf.Config(); cfg != nil {
+			for _, name := range cfg.GetAllDataSources() {
+				nameSet[name] = true
+			}
+		}
+		for name := range engine.GetAllDataSources() {
+			nameSet[name] = true
+		}
+		for name := range dbs {
+			nameSet[name] = true
+		}
+		if len(nameSet) == 0 {
+			return details, errors.New("no datasource configured")
+		}
+		names := make([]string, 0, len(nameSet))
+		for name := range nameSet {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var errs []error
+		for _, name := range names {
+			err := pingDB(ctx, engine, dbs[name], name)
+			if err != nil {
+				details[name] = StatusDown + ": " + err.Error()
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			details[name] = StatusUp
+		}
+		return details, errors.Join(errs...)
+	})
+}
+
+// pingDB db 为 nil 时先初始化数据源，初始化失败时 GetDB 会 panic
+func pingDB(ctx context.Context, engine *lv_db.Engine, db *gorm.DB, name string) (err error) {
+	if db == nil {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("not initialized: %v", r)
+			}
+		}()
+		db = engine.GetDB(name)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// CacheHealthChecker 写入并读取一个短期的 key 检查缓存是否可用
+func CacheHealthChecker() HealthChecker {
+	return HealthCheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		details := map[string]any{"type": lv_conf.Config().GetValueStr(lv_global.KEY_CACHE_TYPE)}
+		cache := lv_cache.GetCacheClient()
+		if err := cache.Set("lv_health:ping", "1", 10*time.Second); err != nil {
+			return details, err
+		}
+		_, err := cache.Exists("lv_health:ping")
+		return details, err
+	})
+}
+
+// DiskSpaceHealthChecker 检查 path 所在磁盘的可用空间，低于 threshold 字节时为 DOWN
+func DiskSpaceHealthChecker(path string, threshold uint64) HealthChecker {
+	return HealthCheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		free, total, err := diskUsage(path)
+		if err != nil {
+			return map[string]any{"path": path}, err
+		}
+		details := map[string]any{"path": path, "free": free, "total": total, "threshold": threshold}
+		if free < threshold {
+			return details, fmt.Errorf("free disk space %d below threshold %d", free, threshold)
+		}
+		return details, nil
+	})
+}
+
+// registerHealthFromConf 注册健康检查接口和框架自带的检查，application.health.enabled 为 true 时生效
+//
+//	application:
+//	  health:
+//	    enabled: true
+//	    path: /actuator/health
+//	    timeout: 3s
+//	    disk-threshold: 10485760 # 上传目录所在磁盘的最小可用空间(字节)
+func registerHealthFromConf(routerBase *gin.RouterGroup) {
+	cfg := lv_conf.Config()
+	if !cfg.GetBool(lv_global.KEY_HEALTH + ".enabled") {
+		return
+	}
+	path := cfg.GetValueStr(lv_global.KEY_HEALTH + ".path")
+	if path == "" {
+		path = "/actuator/health"
+	}
+	timeout := lv_conf.Config().GetDuration(lv_global.KEY_HEALTH+".timeout", 3*time.Second)
+	RegisterHealthChecker("db", DatabaseHealthChecker())
+	RegisterHealthChecker("cache", CacheHealthChecker())
+	uploadPath := cfg.GetUploadPath()
+	if uploadPath == "" {
+		uploadPath = "."
+	}
+	threshold := uint64(10 << 20)
+	if v := cfg.GetValueStr(lv_global.KEY_HEALTH + ".disk-threshold"); v != "" {
+		threshold = cast.ToUint64(v)
+	}
+	RegisterHealthChecker("diskSpace", DiskSpaceHealthChecker(uploadPath, threshold))
+	RegisterHealth(routerBase, path, timeout)
+	lv_log.Info("Health: ", path, " /health/live /health/ready")
+}
+
+// RegisterHealth 注册健康检查接口，正常时返回200，否则返回503
+//
+//	path          所有检查的结果
+//	/health/live  存活检查，进程能处理请求即为 UP
+//	/health/ready 就绪检查，正在关闭或有检查为 DOWN 时为 DOWN
+func RegisterHealth(routerBase *gin.RouterGroup, path string, timeout time.Duration) {
+	routerBase.GET(path, func(c *gin.Context) {
+		writeHealth(c, CheckHealth(c.Request.Context(), timeout))
+	})
+	routerBase.GET("/health/live", func(c *gin.Context) {
+		writeHealth(c, &Health{Status: StatusUp})
+	})
+	routerBase.GET("/health/ready", func(c *gin.Context) {
+		if !App().Ready() {
+			writeHealth(c, &Health{Status: StatusDown, Details: map[string]any{"stopping": App().Stopping()}})
+			return
+		}
+		writeHealth(c, CheckHealth(c.Request.Context(), timeout))
+	})
+}
+
+func writeHealth(c *gin.Context, h *Health) {
+	status := http.StatusOK
+	if h.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, h)
+}
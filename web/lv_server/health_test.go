@@ -0,0 +1,65 @@
+package lv_server
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_db"
+	"github.com/spf13/cast"
+)
+
+func TestCheckHealth(t *testing.T) {
+	healthCheckers = make(map[string]HealthChecker)
+	RegisterHealthChecker("ok", HealthCheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		return map[string]any{"version": "1"}, nil
+	}))
+	RegisterHealthChecker("disk", DiskSpaceHealthChecker(".", 0))
+	h := CheckHealth(context.Background(), time.Second)
+	if h.Status != StatusUp || h.Components["ok"].Details["version"] != "1" || h.Components["disk"].Details["total"].(uint64) == 0 {
+		t.Fatal(h)
+	}
+
+	RegisterHealthChecker("slow", HealthCheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}))
+	RegisterHealthChecker("panic", HealthCheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		panic("boom")
+	}))
+	RegisterHealthChecker("disk", DiskSpaceHealthChecker(".", math.MaxUint64))
+	RegisterHealthChecker("down", HealthCheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		return nil, errors.New("connection refused")
+	}))
+	h = CheckHealth(context.Background(), 50*time.Millisecond)
+	if h.Status != StatusDown || h.Components["ok"].Status != StatusUp {
+		t.Fatal(h)
+	}
+	for name, want := range map[string]string{"slow": context.DeadlineExceeded.Error(), "panic": "boom", "down": "connection refused"} {
+		if c := h.Components[name]; c.Status != StatusDown || c.Details["error"] != want {
+			t.Fatal(name, c)
+		}
+	}
+	if h.Components["disk"].Status != StatusDown {
+		t.Fatal(h.Components["disk"])
+	}
+}
+
+func TestDatabaseHealthChecker(t *testing.T) {
+	checker := DatabaseHealthChecker()
+	if _, err := checker.Check(context.Background()); err == nil {
+		t.Fatal("no datasource should be DOWN")
+	}
+
+	// 创建连接失败的数据源也要检查
+	if _, err := lv_db.GetInstance().CreateAndRegisterDB(&lv_db.DataSource{Name: "broken", Driver: "unknown"}); err == nil {
+		t.Fatal("unknown driver should fail")
+	}
+	details, err := checker.Check(context.Background())
+	if err == nil || !strings.HasPrefix(cast.ToString(details["broken"]), StatusDown) {
+		t.Fatal(details, err)
+	}
+}
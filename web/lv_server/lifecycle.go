@@ -0,0 +1,167 @@
+package lv_server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/lostvip-com/lv_framework/lv_cache"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_db"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/spf13/cast"
+)
+
+// Lifecycle 应用生命周期：收到 SIGINT/SIGTERM 后标记为未就绪，停止接收新连接并等待处理中的请求完成，
+// 再按注册的逆序执行关闭钩子，先注册的(被依赖的)最后关闭
+type Lifecycle struct {
+	mu       sync.Mutex
+	hooks    []shutdownHook
+	ready    atomic.Bool
+	stopping atomic.Bool
+	once     sync.Once
+	done     chan struct{}
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	appOnce sync.Once
+	app     *Lifecycle
+)
+
+// App 默认的生命周期管理，已注册日志、数据库、缓存的关闭钩子，关闭顺序为 缓存 -> 数据库 -> 日志
+func App() *Lifecycle {
+	appOnce.Do(func() {
+		app = NewLifecycle()
+		app.OnShutdown("log", func(ctx context.Context) error {
+			return lv_log.Close()
+		})
+		app.OnShutdown("database", func(ctx context.Context) error {
+			lv_db.ShutdownDatabase()
+			return nil
+		})
+		app.OnShutdown("cache", func(ctx context.Context) error {
+			return lv_cache.CloseCacheClient()
+		})
+	})
+	return app
+}
+
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{done: make(chan struct{})}
+}
+
+// OnShutdown 注册关闭钩子，ctx 的超时时间为 server.shutdown.timeout 剩余的时间
+func (l *Lifecycle) OnShutdown(name string, fn func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Ready 是否可以接收流量，用于就绪检查
+func (l *Lifecycle) Ready() bool {
+	return l.ready.Load() && !l.stopping.Load()
+}
+
+// SetReady 设置就绪状态，例如预热完成后再设置为 true
+func (l *Lifecycle) SetReady(ready bool) {
+	l.ready.Store(ready)
+}
+
+// Stopping 是否正在关闭
+func (l *Lifecycle) Stopping() bool {
+	return l.stopping.Load()
+}
+
+// Done 关闭完成后 close
+func (l *Lifecycle) Done() <-chan struct{} {
+	return l.done
+}
+
+// Shutdown 标记为未就绪，执行 drain(通常为 http.Server.Shutdown)，再逆序执行关闭钩子，只执行一次
+func (l *Lifecycle) Shutdown(ctx context.Context, drain func(ctx context.Context) error) error {
+	var err error
+	l.once.Do(func() {
+		defer close(l.done)
+		l.stopping.Store(true)
+		if drain != nil {
+			if e := drain(ctx); e != nil {
+				lv_log.Error("drain requests error:", e)
+				err = e
+			}
+		}
+		l.mu.Lock()
+		hooks := append([]shutdownHook(nil), l.hooks...)
+		l.mu.Unlock()
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if e := runHook(ctx, hooks[i]); e != nil {
+				lv_log.Error("shutdown hook ", hooks[i].name, " error:", e)
+				err = errors.Join(err, e)
+			}
+		}
+	})
+	return err
+}
+
+// runHook 钩子超时后不再等待，继续执行后面的钩子；已经超时的，剩余的钩子各有 hookGrace 的时间释放资源
+func runHook(ctx context.Context, hook shutdownHook) error {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), hookGrace)
+		defer cancel()
+	}
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- errors.New(cast.ToString(r))
+			}
+		}()
+		result <- hook.fn(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+const hookGrace = time.Second
+
+// WaitSignal 阻塞直到收到 SIGINT/SIGTERM 或 ctx 结束
+func WaitSignal(ctx context.Context) os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(ch)
+	select {
+	case sig := <-ch:
+		return sig
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// shutdownTimeout 等待请求完成和执行钩子的总时间，默认30s
+// shutdownDelay 标记为未就绪后等待的时间，便于负载均衡摘除流量，默认0
+//
+//	server:
+//	  shutdown:
+//	    timeout: 30s
+//	    delay: 5s
+func shutdownTimeout() time.Duration {
+	return lv_conf.Config().GetDuration("server.shutdown.timeout", 30*time.Second)
+}
+
+func shutdownDelay() time.Duration {
+	return lv_conf.Config().GetDuration("server.shutdown.delay", 0)
+}
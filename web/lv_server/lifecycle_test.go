@@ -0,0 +1,52 @@
+package lv_server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLifecycleShutdown(t *testing.T) {
+	l := NewLifecycle()
+	l.SetReady(true)
+	var order []string
+	for _, name := range []string{"log", "database", "cache"} {
+		l.OnShutdown(name, func(ctx context.Context) error {
+			order = append(order, name)
+			if name == "database" {
+				return errors.New("close failed")
+			}
+			return nil
+		})
+	}
+	l.OnShutdown("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	drained := false
+	err := l.Shutdown(ctx, func(ctx context.Context) error {
+		if l.Ready() {
+			t.Error("ready during drain")
+		}
+		drained = true
+		return nil
+	})
+	if !drained || strings.Join(order, ",") != "cache,database,log" {
+		t.Fatal(drained, order)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "close failed") {
+		t.Fatal(err)
+	}
+	select {
+	case <-l.Done():
+	default:
+		t.Fatal("not done")
+	}
+	if err = l.Shutdown(context.Background(), nil); err != nil { //只执行一次
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,294 @@
+package lv_server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/web/lv_middleware"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	ListenerMain = "main" // server.port 对应的监听，server.listeners 中同名的配置会覆盖它
+
+	ModeApp   = "app"   // 业务路由
+	ModeAdmin = "admin" // 健康检查、指标、pprof
+
+	NetworkTcp     = "tcp"
+	NetworkUnix    = "unix"
+	NetworkSystemd = "systemd" // systemd socket activation，addr 为 FileDescriptorName 或序号
+)
+
+// ListenerConfig 监听配置，配置了 admin 监听后，健康检查和指标只在 admin 监听上提供
+//
+//	server:
+//	  port: 8080
+//	  host: 0.0.0.0
+//	  h2c: true
+//	  listeners:
+//	    - name: admin
+//	      addr: 127.0.0.1:9090
+//	      mode: admin
+//	      pprof: true
+//	    - name: sock
+//	      network: unix
+//	      addr: /run/app/app.sock
+//	      file-mode: "0660"
+//	    - name: main             # 覆盖 server.port 的监听，例如由 systemd 创建
+//	      network: systemd
+//	      addr: http
+type ListenerConfig struct {
+	Name     string `mapstructure:"name"`
+	Network  string `mapstructure:"network"` // tcp(默认)/unix/systemd
+	Addr     string `mapstructure:"addr"`
+	Mode     string `mapstructure:"mode"` // app(默认)/admin
+	H2C      bool   `mapstructure:"h2c"`  // 不使用 tls 的 http/2
+	TLS      bool   `mapstructure:"tls"`  // 使用 server.cert/server.key/server.tls 的配置
+	Pprof    bool   `mapstructure:"pprof"`
+	FileMode string `mapstructure:"file-mode"` // unix socket 文件权限，默认 0660
+}
+
+// Listener 一个监听及其 http.Server，Use 添加只对该监听生效的中间件
+type Listener struct {
+	ListenerConfig
+	Server     *http.Server
+	middleware []func(http.Handler) http.Handler
+}
+
+// Use 添加中间件，需要在 ListenAndServe 之前调用，先添加的在外层
+func (l *Listener) Use(middleware ...func(http.Handler) http.Handler) *Listener {
+	l.middleware = append(l.middleware, middleware...)
+	return l
+}
+
+// handler 在 base 外层加上该监听的中间件和 h2c
+func (l *Listener) handler(base http.Handler) http.Handler {
+	h := base
+	for i := len(l.middleware) - 1; i >= 0; i-- {
+		h = l.middleware[i](h)
+	}
+	if l.H2C && !l.TLS {
+		h = h2c.NewHandler(h, &http2.Server{})
+	}
+	return h
+}
+
+// listen 按 network 创建监听
+func (l *Listener) listen() (net.Listener, error) {
+	switch l.Network {
+	case "", NetworkTcp:
+		return net.Listen("tcp", l.Addr)
+	case NetworkUnix:
+		if info, err := os.Lstat(l.Addr); err == nil && info.Mode()&fs.ModeSocket != 0 {
+			_ = os.Remove(l.Addr) //上次异常退出残留的 socket 文件
+		}
+		ln, err := net.Listen("unix", l.Addr)
+		if err != nil {
+			return nil, err
+		}
+		mode := uint64(0660)
+		if l.FileMode != "" {
+			if mode, err = strconv.ParseUint(l.FileMode, 8, 32); err != nil {
+				ln.Close()
+				return nil, fmt.Errorf("invalid file-mode %s: %w", l.FileMode, err)
+			}
+		}
+		if err = os.Chmod(l.Addr, os.FileMode(mode)); err != nil {
+			ln.Close()
+			return nil, err
+		}
+		return ln, nil
+	case NetworkSystemd:
+		return systemdListener(l.Addr)
+	}
+	return nil, fmt.Errorf("unknown network %s of listener %s", l.Network, l.Name)
+}
+
+func (l *Listener) String() string {
+	network := l.Network
+	if network == "" {
+		network = NetworkTcp
+	}
+	proto := "http"
+	if l.TLS {
+		proto = "https"
+	} else if l.H2C {
+		proto = "h2c"
+	}
+	return fmt.Sprintf("%s %s %s://%s", l.Name, network, proto, l.Addr)
+}
+
+// serve 在已创建的监听上提供服务，直到 Shutdown，Server.Handler 需在启动前由 prepareHandlers 设置好
+func (l *Listener) serve(ln net.Listener, tlsCfg *tls.Config) error {
+	if l.TLS {
+		if tlsCfg == nil {
+			return fmt.Errorf("listener %s requires tls config", l.Name)
+		}
+		l.Server.TLSConfig = tlsCfg
+		return l.Server.ServeTLS(ln, "", "")
+	}
+	return l.Server.Serve(ln)
+}
+
+// prepareHandlers 在启动任何监听前设置各监听的 Server.Handler，
+// 未指定 handler 的监听使用主监听原始的 handler，不继承主监听的中间件和 h2c
+func (s *MyHttpServer) prepareHandlers() {
+	base := s.HttpServer.Handler
+	for _, l := range s.Listeners {
+		h := l.Server.Handler
+		if h == nil {
+			h = base
+		}
+		l.Server.Handler = l.handler(h)
+	}
+}
+
+// AddListener 添加监听，handler 为空时在启动时使用主监听的 handler
+func (s *MyHttpServer) AddListener(cfg ListenerConfig, handler http.Handler) *Listener {
+	l := &Listener{ListenerConfig: cfg, Server: &http.Server{
+		Handler:      handler,
+		ReadTimeout:  s.HttpServer.ReadTimeout,
+		WriteTimeout: s.HttpServer.WriteTimeout,
+	}}
+	s.Listeners = append(s.Listeners, l)
+	return l
+}
+
+// Listener 按名称查找监听
+func (s *MyHttpServer) Listener(name string) *Listener {
+	for _, l := range s.Listeners {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// LoadListenerConfigs 读取 server.listeners
+func LoadListenerConfigs() []ListenerConfig {
+	var configs []ListenerConfig
+	if lv_conf.Config().GetVipperCfg() == nil {
+		return nil
+	}
+	if err := lv_conf.Config().GetVipperCfg().UnmarshalKey("server.listeners", &configs); err != nil {
+		lv_log.Error("server.listeners config error:", err)
+	}
+	for i := range configs {
+		if configs[i].Name == "" {
+			configs[i].Name = "listener" + strconv.Itoa(i)
+		}
+	}
+	return configs
+}
+
+func hasAdminListener(configs []ListenerConfig) bool {
+	for _, cfg := range configs {
+		if cfg.Mode == ModeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// bindAddr 主监听的地址，依次使用 server.host、server.ip，都未配置时监听所有网卡
+func bindAddr() string {
+	host := lv_conf.Config().GetValueStr("server.host")
+	if host == "" {
+		host = lv_conf.Config().GetValueStr("server.ip")
+	}
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(lv_conf.Config().GetServerPort()))
+}
+
+// NewAdminEngine 管理端口的路由：健康检查和指标(已开启时)，pprof 为 true 时注册 /debug/pprof
+func NewAdminEngine(withPprof bool) *gin.Engine {
+	engine := gin.New()
+	engine.Use(lv_middleware.RecoverError)
+	root := &engine.RouterGroup
+	registerHealthFromConf(root)
+	if lv_conf.Config().GetBool(lv_global.KEY_METRICS + ".enabled") {
+		registerMetrics(root)
+	}
+	if withPprof {
+		debug := engine.Group("/debug/pprof")
+		debug.GET("/", gin.WrapF(pprof.Index))
+		debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/profile", gin.WrapF(pprof.Profile))
+		debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+		debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/trace", gin.WrapF(pprof.Trace))
+		debug.GET("/:name", func(c *gin.Context) {
+			pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+		})
+	}
+	return engine
+}
+
+var (
+	systemdOnce  sync.Once
+	systemdFiles map[string]*os.File
+	systemdErr   error
+)
+
+// systemdListener systemd socket activation 传入的监听，name 为 FileDescriptorName 或从0开始的序号
+func systemdListener(name string) (net.Listener, error) {
+	systemdOnce.Do(func() {
+		systemdFiles, systemdErr = systemdSockets()
+	})
+	if systemdErr != nil {
+		return nil, systemdErr
+	}
+	f, ok := systemdFiles[name]
+	if !ok {
+		return nil, fmt.Errorf("systemd socket %s not found", name)
+	}
+	for key, file := range systemdFiles {
+		if file == f {
+			delete(systemdFiles, key) //同一个描述符以序号和名称各保存了一次
+		}
+	}
+	ln, err := net.FileListener(f)
+	f.Close() //FileListener 会复制文件描述符
+	return ln, err
+}
+
+// systemdSockets 按 LISTEN_PID/LISTEN_FDS/LISTEN_FDNAMES 读取文件描述符，从3开始
+func systemdSockets() (map[string]*os.File, error) {
+	if pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID")); pid != os.Getpid() {
+		return nil, errors.New("no systemd sockets passed to this process")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, errors.New("invalid LISTEN_FDS")
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	files := make(map[string]*os.File, count*2)
+	for i := 0; i < count; i++ {
+		fd := uintptr(3 + i)
+		f := os.NewFile(fd, "systemd-"+strconv.Itoa(i))
+		files[strconv.Itoa(i)] = f
+		if i < len(names) && names[i] != "" {
+			files[names[i]] = f
+		}
+	}
+	for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		os.Unsetenv(key) //子进程不再继承
+	}
+	return files, nil
+}
@@ -0,0 +1,103 @@
+package lv_server
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/http2"
+)
+
+func TestListenerUnixAndH2C(t *testing.T) {
+	hello := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	})
+	tagged := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Listener", "sock")
+			next.ServeHTTP(w, r)
+		})
+	}
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	unixL := (&Listener{ListenerConfig: ListenerConfig{Name: "sock", Network: NetworkUnix, Addr: sock, FileMode: "0600"},
+		Server: &http.Server{Handler: hello}}).Use(tagged)
+	h2cL := &Listener{ListenerConfig: ListenerConfig{Name: "h2c", Addr: "127.0.0.1:0", H2C: true}, Server: &http.Server{Handler: hello}}
+	for _, l := range []*Listener{unixL, h2cL} {
+		l.Server.Handler = l.handler(l.Server.Handler)
+		ln, err := l.listen()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l == h2cL {
+			l.Addr = ln.Addr().String()
+		}
+		go l.serve(ln, nil)
+		defer l.Server.Close()
+	}
+
+	if info, err := os.Stat(sock); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatal("socket file mode", info, err)
+	}
+	unixClient := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+	}}}
+	resp, err := unixClient.Get("http://unix/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("X-Listener") != "sock" {
+		t.Fatal("listener middleware not applied")
+	}
+
+	h2Client := &http.Client{Transport: &http2.Transport{AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}}}
+	if resp, err = h2Client.Get("http://" + h2cL.Addr + "/"); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/2.0" || resp.Header.Get("X-Listener") != "" {
+		t.Fatal(string(body), resp.Header)
+	}
+
+	if _, err = systemdListener("http"); err == nil {
+		t.Fatal("systemd socket without LISTEN_FDS")
+	}
+}
+
+func TestPrepareHandlers(t *testing.T) {
+	hello := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	})
+	s := &MyHttpServer{HttpServer: &http.Server{Handler: hello}}
+	main := s.AddListener(ListenerConfig{Name: ListenerMain, H2C: true}, nil)
+	main.Server = s.HttpServer
+	main.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Listener", ListenerMain)
+			next.ServeHTTP(w, r)
+		})
+	})
+	app := s.AddListener(ListenerConfig{Name: "app"}, nil)
+	s.prepareHandlers()
+
+	w := httptest.NewRecorder()
+	main.Server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Header().Get("X-Listener") != ListenerMain {
+		t.Fatal("main listener middleware not applied")
+	}
+	w = httptest.NewRecorder()
+	app.Server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "hello" || w.Header().Get("X-Listener") != "" {
+		t.Fatal("app listener inherited main listener middleware", w.Header())
+	}
+}
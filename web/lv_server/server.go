@@ -19,6 +19,7 @@ package lv_server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -26,34 +27,43 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
 	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/lv_metrics"
 	"github.com/lostvip-com/lv_framework/utils/lv_err"
 	"github.com/lostvip-com/lv_framework/web/lv_middleware"
 	"github.com/lostvip-com/lv_framework/web/lv_router"
 	"github.com/spf13/cast"
 )
 
-// MyHttpServer 统一支持 HTTP/HTTPS
+// MyHttpServer 统一支持 HTTP/HTTPS，可以通过 server.listeners 配置多个监听
 type MyHttpServer struct {
-	HttpServer *http.Server
+	HttpServer *http.Server //主监听，即 server.port
 	//grcServer
-	ServerName string
+	ServerName     string
+	RedirectServer *http.Server //https 时将 http 跳转到 https，配置 server.tls.redirect-port 后启用
+	Listeners      []*Listener  //第一个为主监听
 }
 
 func (s *MyHttpServer) ListenAndServe() {
 	s.printBanner()
 
-	ssl := lv_conf.Config().GetBool("server.ssl")
-	certFile := lv_conf.Config().GetValueStr("server.cert")
-	keyFile := lv_conf.Config().GetValueStr("server.key")
-
-	var err error
-	if ssl {
-		lv_log.Info("⛲  HTTPS Server Listen: ", s.HttpServer.Addr)
-		err = s.HttpServer.ListenAndServeTLS(certFile, keyFile)
-	} else {
-		lv_log.Info("⛲  HTTP Server Listen: ", s.HttpServer.Addr)
-		err = s.HttpServer.ListenAndServe()
+	lns, err := s.listen()
+	if err == nil {
+		App().SetReady(true)
+		errCh := make(chan error, len(lns))
+		s.prepareHandlers()
+		for i, l := range s.Listeners {
+			lv_log.Info("⛲  Server Listen: ", l)
+			go func(l *Listener, ln net.Listener) {
+				errCh <- l.serve(ln, s.HttpServer.TLSConfig)
+			}(l, lns[i])
+		}
+		for range lns {
+			if err = <-errCh; err != nil && err != http.ErrServerClosed {
+				break
+			}
+		}
 	}
 
 	// 区分正常关闭和异常错误
@@ -66,14 +76,78 @@ func (s *MyHttpServer) ListenAndServe() {
 	lv_log.Info("❌  Server exited.")
 }
 
-// ShutDown 暴露给外部手动调用
+// listen 创建所有监听，有一个失败时关闭已创建的监听
+func (s *MyHttpServer) listen() ([]net.Listener, error) {
+	for _, l := range s.Listeners {
+		if l.TLS {
+			if err := s.setupTLS(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	lns := make([]net.Listener, 0, len(s.Listeners))
+	for _, l := range s.Listeners {
+		ln, err := l.listen()
+		if err != nil {
+			for _, opened := range lns {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("listener %s: %w", l.Name, err)
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// setupTLS 按 server.tls 配置证书自动加载、最低版本、加密套件和双向认证，并启动 http 跳转
+func (s *MyHttpServer) setupTLS() error {
+	cfg := LoadTLSConfig()
+	tlsCfg, _, err := cfg.Build()
+	if err != nil {
+		return err
+	}
+	s.HttpServer.TLSConfig = tlsCfg
+	if cfg.RedirectPort > 0 && s.RedirectServer == nil {
+		s.RedirectServer = redirectServer(cfg.RedirectPort, s.HttpServer.Addr)
+		go func() {
+			lv_log.Info("⛲  HTTP Redirect Listen: ", s.RedirectServer.Addr)
+			if err := s.RedirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				lv_log.Error("redirect server error:", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// Run 启动服务并阻塞，收到 SIGINT/SIGTERM 后优雅关闭，关闭完成后返回
+func (s *MyHttpServer) Run() error {
+	go s.ListenAndServe()
+	sig := WaitSignal(context.Background())
+	lv_log.Info("received signal ", sig, ", shutting down...")
+	return s.ShutDown()
+}
+
+// ShutDown 暴露给外部手动调用：标记为未就绪，等待 server.shutdown.delay 后停止接收新连接，
+// 在 server.shutdown.timeout 内等待处理中的请求完成，再依次关闭缓存、数据库、日志
 func (s *MyHttpServer) ShutDown() error {
 	if s.HttpServer == nil {
 		return fmt.Errorf("http server is nil")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	lifecycle := App()
+	lifecycle.SetReady(false)
+	if delay := shutdownDelay(); delay > 0 {
+		time.Sleep(delay)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
 	defer cancel()
-	return s.HttpServer.Shutdown(ctx)
+	servers := []*http.Server{s.HttpServer, s.RedirectServer}
+	for _, l := range s.Listeners {
+		if l.Server != s.HttpServer {
+			servers = append(servers, l.Server)
+		}
+	}
+	return lifecycle.Shutdown(ctx, shutdownServers(servers...))
 }
 
 // printBanner 打印控制台地址
@@ -99,15 +173,15 @@ func (s *MyHttpServer) printBanner() {
 	fmt.Println(strings.Repeat("#", 62))
 }
 
-// NewHttpServer 构造器：读取配置，自动区分 HTTP/HTTPS
+// NewHttpServer 构造器：读取配置，自动区分 HTTP/HTTPS，按 server.listeners 添加监听
 func NewHttpServer() *MyHttpServer {
 	gin.DefaultWriter = lv_log.GetLog().GetLogWriter()
 	contextPath := lv_conf.Config().GetContextPath()
-	port := lv_conf.Config().GetServerPort()
+	configs := LoadListenerConfigs()
 	httpServer := &MyHttpServer{ServerName: lv_conf.Config().GetAppName()}
 	httpServer.HttpServer = &http.Server{
-		Addr:    "0.0.0.0:" + cast.ToString(port),
-		Handler: InitGinRouter(contextPath),
+		Addr:    bindAddr(),
+		Handler: initGinRouter(contextPath, !hasAdminListener(configs)),
 	}
 	timeoutR := lv_conf.Config().GetValueStr("server.read-timeout")
 	if timeoutR != "" {
@@ -115,19 +189,59 @@ func NewHttpServer() *MyHttpServer {
 	}
 	timeoutW := lv_conf.Config().GetValueStr("server.write-timeout")
 	if timeoutW != "" {
-		httpServer.HttpServer.ReadTimeout = cast.ToDuration(timeoutW)
+		httpServer.HttpServer.WriteTimeout = cast.ToDuration(timeoutW)
+	}
+	main := httpServer.AddListener(ListenerConfig{
+		Name: ListenerMain,
+		Addr: httpServer.HttpServer.Addr,
+		Mode: ModeApp,
+		H2C:  lv_conf.Config().GetBool("server.h2c"),
+		TLS:  lv_conf.Config().GetBool("server.ssl"),
+	}, nil)
+	main.Server = httpServer.HttpServer
+	for _, cfg := range configs {
+		if cfg.Name == ListenerMain {
+			if cfg.Addr == "" {
+				cfg.Addr = main.Addr
+			}
+			cfg.Mode, cfg.TLS = ModeApp, cfg.TLS || main.TLS
+			main.ListenerConfig = cfg
+			if cfg.Network == "" || cfg.Network == NetworkTcp {
+				httpServer.HttpServer.Addr = cfg.Addr
+			}
+			continue
+		}
+		var handler http.Handler
+		if cfg.Mode == ModeAdmin {
+			handler = NewAdminEngine(cfg.Pprof)
+		}
+		httpServer.AddListener(cfg, handler)
 	}
 	return httpServer
 }
 
 // InitGinRouter 保持不变
 func InitGinRouter(contextPath string) *gin.Engine {
+	return initGinRouter(contextPath, true)
+}
+
+// initGinRouter withAdmin 为 false 时健康检查和指标接口由 admin 监听提供
+func initGinRouter(contextPath string, withAdmin bool) *gin.Engine {
 	engine := gin.Default()
 	///////////////////////中间件处理start////////////////////////////////////////////////
 	engine.Use(lv_middleware.RecoverError)
+	// 紧跟在 RecoverError 之后，被限流、Options 等中间件直接返回的请求也要统计
+	metricsEnabled := lv_conf.Config().GetBool(lv_global.KEY_METRICS + ".enabled")
+	if metricsEnabled {
+		engine.Use(lv_metrics.Middleware())
+	}
 	engine.Use(lv_middleware.SetTraceId)
+	lv_middleware.LoadCorsConfig() //启动时检查跨域配置，Options 在第一次请求时才加载
 	engine.Use(lv_middleware.Options)
 	engine.Use(lv_middleware.LoggerURI())
+	if lv_conf.Config().GetBool(lv_global.KEY_RATE_LIMIT + ".enabled") {
+		engine.Use(lv_middleware.RateLimit())
+	}
 	//////////////////////////////////////////////////////////////////////////////////
 	routerBase := engine.Group(contextPath)
 	tmp, _ := os.Getwd()
@@ -135,6 +249,14 @@ func InitGinRouter(contextPath string) *gin.Engine {
 	fmt.Println("Static Path：" + staticPath)
 	routerBase.StaticFS("/static", http.Dir(staticPath))
 	routerBase.StaticFile("/favicon.ico", staticPath+"/favicon.ico")
+	registerCacheAdminFromConf()
+	registerOpenAPI(routerBase)
+	if withAdmin {
+		registerHealthFromConf(routerBase)
+		if metricsEnabled {
+			registerMetrics(routerBase)
+		}
+	}
 	// 注册业务路由
 	if len(lv_router.GroupList) > 0 {
 		for _, group := range lv_router.GroupList {
@@ -150,3 +272,60 @@ func InitGinRouter(contextPath string) *gin.Engine {
 	}
 	return engine
 }
+
+// registerMetrics 注册 Prometheus 指标接口，默认路径为 /metrics
+//
+//	application:
+//	  metrics:
+//	    enabled: true
+//	    path: /metrics
+func registerMetrics(routerBase *gin.RouterGroup) {
+	path := lv_conf.Config().GetValueStr(lv_global.KEY_METRICS + ".path")
+	if path == "" {
+		path = "/metrics"
+	}
+	routerBase.GET(path, lv_metrics.Handler())
+	lv_log.Info("Metrics: ", path)
+}
+
+// registerOpenAPI 注册接口文档和 Swagger UI，application.openapi.enabled 为 true 时生效，
+// 文档包含所有路由及权限标识且不需要登录，生产环境不建议开启；环境变量或配置 SwaggerOff 为 true 时强制关闭
+//
+//	application:
+//	  openapi:
+//	    enabled: true
+//	    path: /v3/api-docs
+//	    ui-path: /swagger-ui
+//	    title: lv
+//	    version: 1.0.0
+func registerOpenAPI(routerBase *gin.RouterGroup) {
+	if !lv_conf.Config().GetBool(lv_global.KEY_OPENAPI + ".enabled") {
+		return
+	}
+	if cast.ToBool(os.Getenv(lv_global.KEY_SWAGGER_OFF)) || lv_conf.Config().GetBool(lv_global.KEY_SWAGGER_OFF) {
+		return
+	}
+	docPath := lv_conf.Config().GetValueStr(lv_global.KEY_OPENAPI + ".path")
+	if docPath == "" {
+		docPath = "/v3/api-docs"
+	}
+	uiPath := lv_conf.Config().GetValueStr(lv_global.KEY_OPENAPI + ".ui-path")
+	if uiPath == "" {
+		uiPath = "/swagger-ui"
+	}
+	info := lv_router.OpenAPIInfo{
+		Title:     lv_conf.Config().GetValueStr(lv_global.KEY_OPENAPI + ".title"),
+		Version:   lv_conf.Config().GetValueStr(lv_global.KEY_OPENAPI + ".version"),
+		ServerUrl: routerBase.BasePath(),
+	}
+	if info.Title == "" {
+		info.Title = lv_conf.Config().GetAppName()
+	}
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+	routerBase.GET(docPath, lv_router.OpenAPIHandler(info))
+	routerBase.GET(uiPath, lv_router.SwaggerUIHandler(strings.TrimSuffix(routerBase.BasePath(), "/")+docPath,
+		lv_conf.Config().GetValueStr(lv_global.KEY_OPENAPI+".cdn")))
+	lv_log.Info("OpenAPI: ", docPath, " Swagger UI: ", uiPath)
+}
@@ -0,0 +1,283 @@
+package lv_server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_log"
+)
+
+// TLSConfig https 配置，证书文件变化后自动重新加载，不需要重启
+//
+//	server:
+//	  ssl: true
+//	  cert: cert/server.crt
+//	  key: cert/server.key
+//	  tls:
+//	    min-version: "1.2"
+//	    ciphers: [TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256]
+//	    reload-interval: 30s
+//	    redirect-port: 80          # 在该端口监听 http 并跳转到 https
+//	    client-auth: require       # none/request/verify-if-given/require
+//	    client-ca: cert/ca.crt
+type TLSConfig struct {
+	CertFile       string        `mapstructure:"-"`
+	KeyFile        string        `mapstructure:"-"`
+	MinVersion     string        `mapstructure:"min-version"`
+	Ciphers        []string      `mapstructure:"ciphers"`
+	ReloadInterval time.Duration `mapstructure:"reload-interval"`
+	RedirectPort   int           `mapstructure:"redirect-port"`
+	ClientAuth     string        `mapstructure:"client-auth"`
+	ClientCA       string        `mapstructure:"client-ca"`
+}
+
+// LoadTLSConfig 读取 server.cert、server.key 和 server.tls 配置
+func LoadTLSConfig() *TLSConfig {
+	cfg := &TLSConfig{
+		CertFile: lv_conf.Config().GetValueStr("server.cert"),
+		KeyFile:  lv_conf.Config().GetValueStr("server.key"),
+	}
+	if err := lv_conf.Config().GetVipperCfg().UnmarshalKey("server.tls", cfg); err != nil {
+		lv_log.Error("server.tls config error:", err)
+	}
+	return cfg
+}
+
+// Build 生成 tls.Config，证书通过 CertReloader 加载
+func (cfg *TLSConfig) Build() (*tls.Config, *CertReloader, error) {
+	reloader, err := NewCertReloader(cfg.CertFile, cfg.KeyFile, cfg.ReloadInterval)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsCfg := &tls.Config{GetCertificate: reloader.GetCertificate, MinVersion: tls.VersionTLS12}
+	if cfg.MinVersion != "" {
+		if tlsCfg.MinVersion, err = parseTLSVersion(cfg.MinVersion); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(cfg.Ciphers) > 0 {
+		if tlsCfg.CipherSuites, err = parseCiphers(cfg.Ciphers); err != nil {
+			return nil, nil, err
+		}
+	}
+	if tlsCfg.ClientAuth, err = parseClientAuth(cfg.ClientAuth); err != nil {
+		return nil, nil, err
+	}
+	if cfg.ClientCA != "" {
+		pem, err := os.ReadFile(cfg.ClientCA)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsCfg.ClientCAs = x509.NewCertPool()
+		if !tlsCfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificate found in %s", cfg.ClientCA)
+		}
+	} else if tlsCfg.ClientAuth >= tls.VerifyClientCertIfGiven {
+		return nil, nil, errors.New("server.tls.client-ca is required to verify client certificates")
+	}
+	return tlsCfg, reloader, nil
+}
+
+func parseTLSVersion(v string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToUpper(v), "TLS") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unknown tls version: %s", v)
+}
+
+// parseCiphers 按 Go 中的名称查找加密套件，只对 TLS1.2 及以下有效
+func parseCiphers(names []string) ([]uint16, error) {
+	suites := make(map[string]uint16)
+	for _, s := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites[s.Name] = s.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseClientAuth(v string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(v) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "verify-if-given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return 0, fmt.Errorf("unknown client-auth: %s", v)
+}
+
+// CertReloader 证书文件修改后重新加载，握手时最多每 interval 检查一次文件修改时间
+// 新证书加载失败时继续使用旧证书
+type CertReloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+	mu                sync.RWMutex
+	cert              *tls.Certificate
+	modTime           time.Time
+	checked           time.Time
+}
+
+// NewCertReloader 加载证书，interval 默认30s
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 立即重新加载证书
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	modTime := r.fileModTime()
+	r.mu.Lock()
+	r.cert, r.modTime, r.checked = &cert, modTime, time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// fileModTime 证书和私钥中较新的修改时间
+func (r *CertReloader) fileModTime() time.Time {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		if info, err := os.Stat(f); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// GetCertificate 用于 tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, due := r.cert, time.Since(r.checked) >= r.interval
+	r.mu.RUnlock()
+	if !due {
+		return cert, nil
+	}
+	r.mu.Lock()
+	r.checked = time.Now()
+	changed := !r.fileModTime().Equal(r.modTime)
+	r.mu.Unlock()
+	if changed {
+		if err := r.Reload(); err != nil {
+			lv_log.Error("reload certificate error:", err)
+		} else {
+			lv_log.Info("certificate reloaded: ", r.certFile)
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// redirectServer 将 http 请求 308 跳转到 https 端口
+func redirectServer(port int, httpsAddr string) *http.Server {
+	_, httpsPort, _ := net.SplitHostPort(httpsAddr)
+	return &http.Server{
+		Addr:              ":" + strconv.Itoa(port),
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			host := req.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			if httpsPort != "" && httpsPort != "443" {
+				host = net.JoinHostPort(host, httpsPort)
+			}
+			http.Redirect(w, req, "https://"+host+req.URL.RequestURI(), http.StatusPermanentRedirect)
+		}),
+	}
+}
+
+// ClientIdentity 双向认证时客户端证书中的身份信息
+type ClientIdentity struct {
+	CommonName   string   `json:"commonName"`
+	Organization []string `json:"organization,omitempty"`
+	DNSNames     []string `json:"dnsNames,omitempty"`
+	Emails       []string `json:"emails,omitempty"`
+	URIs         []string `json:"uris,omitempty"`
+	SerialNumber string   `json:"serialNumber"`
+	Fingerprint  string   `json:"fingerprint"` // 证书的 sha256
+	Verified     bool     `json:"verified"`    // 证书链是否已校验，client-auth 为 request 时不校验
+}
+
+// ClientCert 客户端证书，非 https 或客户端未提供证书时返回 nil
+func ClientCert(c *gin.Context) *x509.Certificate {
+	if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return c.Request.TLS.PeerCertificates[0]
+}
+
+// GetClientIdentity 客户端证书中的身份信息，没有客户端证书时返回 nil
+func GetClientIdentity(c *gin.Context) *ClientIdentity {
+	cert := ClientCert(c)
+	if cert == nil {
+		return nil
+	}
+	sum := sha256.Sum256(cert.Raw)
+	id := &ClientIdentity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		Emails:       cert.EmailAddresses,
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+		Verified:     len(c.Request.TLS.VerifiedChains) > 0,
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+	}
+	return id
+}
+
+// shutdownServers 依次关闭多个 http.Server
+func shutdownServers(servers ...*http.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, s := range servers {
+			if s != nil {
+				errs = append(errs, s.Shutdown(ctx))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,128 @@
+package lv_server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// issue 签发证书，parent 为空时自签名
+func issue(t *testing.T, cn string, serial int64, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"lv"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tpl.IsCA, tpl.BasicConstraintsValid, tpl.KeyUsage = true, true, x509.KeyUsageCertSign
+		parent, parentKey = tpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestTLSClientIdentityAndReload(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	ca, caKey, caPem, _ := issue(t, "ca", 1, nil, nil)
+	_, _, srvPem, srvKey := issue(t, "server-1", 2, ca, caKey)
+	_, _, cliPem, cliKey := issue(t, "client-a", 3, ca, caKey)
+	cfg := &TLSConfig{CertFile: write("server.crt", srvPem), KeyFile: write("server.key", srvKey),
+		ClientCA: write("ca.crt", caPem), ClientAuth: "require", MinVersion: "1.3", ReloadInterval: time.Millisecond}
+	tlsCfg, _, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", func(c *gin.Context) { c.JSON(http.StatusOK, GetClientIdentity(c)) })
+	srv := httptest.NewUnstartedServer(r) //StartTLS 会设置 Certificates，这里直接使用 tls 监听
+	srv.Listener = tls.NewListener(srv.Listener, tlsCfg)
+	srv.Start()
+	defer srv.Close()
+	url := strings.Replace(srv.URL, "http://", "https://", 1) + "/me"
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	clientCert, _ := tls.X509KeyPair(cliPem, cliKey)
+	get := func(certs ...tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		return client.Get(url)
+	}
+	resp, err := get(clientCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.TLS.PeerCertificates[0].Subject.CommonName != "server-1" || !strings.Contains(string(body), `"commonName":"client-a"`) || !strings.Contains(string(body), `"verified":true`) {
+		t.Fatal(string(body))
+	}
+	if _, err = get(); err == nil {
+		t.Fatal("request without client certificate accepted")
+	}
+
+	_, _, srvPem, srvKey = issue(t, "server-2", 4, ca, caKey)
+	write("server.crt", srvPem)
+	write("server.key", srvKey)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(cfg.CertFile, future, future)
+	time.Sleep(5 * time.Millisecond)
+	if resp, err = get(clientCert); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if cn := resp.TLS.PeerCertificates[0].Subject.CommonName; cn != "server-2" {
+		t.Fatal("certificate not reloaded:", cn)
+	}
+
+	if _, _, err = (&TLSConfig{CertFile: cfg.CertFile, KeyFile: cfg.KeyFile, ClientAuth: "require"}).Build(); err == nil {
+		t.Fatal("client-auth without client-ca accepted")
+	}
+}
+
+func TestRedirectServer(t *testing.T) {
+	for addr, want := range map[string]string{
+		"0.0.0.0:8443": "https://example.com:8443/a?b=1",
+		":443":         "https://example.com/a?b=1",
+	} {
+		w := httptest.NewRecorder()
+		redirectServer(80, addr).Handler.ServeHTTP(w, httptest.NewRequest("POST", "http://example.com:80/a?b=1", nil))
+		if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != want {
+			t.Fatal(addr, w.Code, w.Header())
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package lv_session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_cache"
+	"github.com/lostvip-com/lv_framework/lv_conf"
+	"github.com/lostvip-com/lv_framework/lv_global"
+	"github.com/lostvip-com/lv_framework/lv_log"
+	"github.com/lostvip-com/lv_framework/utils/lv_net"
+)
+
+var ErrTooManySessions = errors.New("该账号登录的会话数已达上限")
+
+// 超出同时在线数时的处理方式
+const (
+	EvictOldest = "evict-oldest" // 踢出最早登录的会话
+	RejectNew   = "reject"       // 拒绝新的登录
+)
+
+const (
+	sessionKeyPrefix = "lv_session:"
+	userKeyPrefix    = "lv_session:user:" // hash，会话ID -> 登录时间，用于限制同时在线数和踢人
+)
+
+// Options 会话配置
+//
+//	application:
+//	  session:
+//	    timeout: 30m
+//	    cookie-name: LVSESSIONID
+//	    secure: auto        # true/false，auto 时https请求才设置Secure
+//	    same-site: lax      # lax/strict/none
+//	    domain:
+//	    max-sessions: 1     # 同一用户同时在线的会话数，0为不限制
+//	    max-sessions-policy: evict-oldest
+type Options struct {
+	Timeout           time.Duration // 无操作超过该时间会话失效，每次请求都会重新计时
+	CookieName        string
+	Path              string
+	Domain            string
+	Secure            string // true/false/auto
+	SameSite          http.SameSite
+	MaxSessions       int
+	MaxSessionsPolicy string
+}
+
+// 限制同时在线数时，同一用户的登录串行执行，等待锁的最长时间及锁的有效期
+const (
+	loginLockWait = 5 * time.Second
+	loginLockTTL  = 10 * time.Second
+)
+
+// Manager 会话管理，会话数据保存在 Cache 中
+// Locker 用于限制同时在线数时串行化同一用户的登录，多实例部署时应使用redis锁
+type Manager struct {
+	Cache  lv_cache.ICache
+	Locker lv_cache.Locker
+	Options
+}
+
+var (
+	defaultManager *Manager
+	defaultOnce    sync.Once
+)
+
+// Default 按 application.session 配置创建的会话管理，使用 lv_cache.GetCacheClient 保存
+func Default() *Manager {
+	defaultOnce.Do(func() {
+		defaultManager = NewManager(lv_cache.GetCacheClient(), LoadOptions())
+		defaultManager.Locker = lv_cache.GetLocker()
+	})
+	return defaultManager
+}
+
+func NewManager(cache lv_cache.ICache, opts Options) *Manager {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Minute
+	}
+	if opts.CookieName == "" {
+		opts.CookieName = "LVSESSIONID"
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	if opts.MaxSessionsPolicy == "" {
+		opts.MaxSessionsPolicy = EvictOldest
+	}
+	return &Manager{Cache: cache, Locker: lv_cache.NewRamLocker(), Options: opts}
+}
+
+// LoadOptions 读取 application.session 配置
+func LoadOptions() Options {
+	cfg := lv_conf.Config()
+	opts := Options{
+		Timeout:           cfg.GetSessionTimeout(30 * time.Minute),
+		CookieName:        cfg.GetValueStr(lv_global.KEY_SESSION + ".cookie-name"),
+		Path:              cfg.GetContextPath(),
+		Domain:            cfg.GetValueStr(lv_global.KEY_SESSION + ".domain"),
+		Secure:            strings.ToLower(cfg.GetValueStr(lv_global.KEY_SESSION + ".secure")),
+		MaxSessions:       cfg.GetInt(lv_global.KEY_SESSION+".max-sessions", 0),
+		MaxSessionsPolicy: cfg.GetValueStr(lv_global.KEY_SESSION + ".max-sessions-policy"),
+	}
+	switch strings.ToLower(cfg.GetValueStr(lv_global.KEY_SESSION + ".same-site")) {
+	case "strict":
+		opts.SameSite = http.SameSiteStrictMode
+	case "none":
+		opts.SameSite = http.SameSiteNoneMode
+	}
+	return opts
+}
+
+// Middleware 使用默认的会话管理
+func Middleware() gin.HandlerFunc {
+	return Default().Middleware()
+}
+
+// Middleware 读取cookie中的会话，请求结束后保存修改并延长有效期
+func (m *Manager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s := m.load(c)
+		c.Set(contextKey, s)
+		c.Next()
+		s = Get(c) //登录后为新的会话
+		if s.destroyed || s.isNew {
+			return
+		}
+		if s.dirty {
+			if err := m.save(s); err != nil {
+				lv_log.Error("save session error:", err)
+			}
+			return
+		}
+		m.touch(s)
+	}
+}
+
+// load 读取会话，cookie不存在或会话已失效时返回未保存的新会话
+func (m *Manager) load(c *gin.Context) *Session {
+	if id, err := c.Cookie(m.CookieName); err == nil && id != "" {
+		if s, err := m.Find(id); err == nil {
+			s.ctx = c
+			return s
+		} else if !lv_cache.IsNil(err) {
+			lv_log.Error("load session error:", err)
+		}
+	}
+	return m.newSession(c)
+}
+
+func (m *Manager) newSession(c *gin.Context) *Session {
+	return &Session{ID: newSessionId(), CreatedAt: time.Now().Unix(), Ip: lv_net.GetRemoteClientIp(c.Request),
+		UserAgent: c.Request.UserAgent(), manager: m, ctx: c, isNew: true}
+}
+
+// Find 按会话ID读取会话
+func (m *Manager) Find(id string) (*Session, error) {
+	data, err := m.Cache.Get(sessionKeyPrefix + id)
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{ID: id, manager: m}
+	if err = json.Unmarshal([]byte(data), s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (m *Manager) save(s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err = m.Cache.Set(sessionKeyPrefix+s.ID, string(data), m.Timeout); err != nil {
+		return err
+	}
+	if s.UserId != "" {
+		m.Cache.Expire(userKeyPrefix+s.UserId, m.Timeout)
+	}
+	return nil
+}
+
+// touch 滑动过期，每次请求重新计算有效期
+func (m *Manager) touch(s *Session) {
+	if err := m.Cache.Expire(sessionKeyPrefix+s.ID, m.Timeout); err != nil {
+		lv_log.Error("touch session error:", err)
+	}
+	if s.UserId != "" {
+		m.Cache.Expire(userKeyPrefix+s.UserId, m.Timeout)
+	}
+}
+
+// Login 登录成功后调用，旧会话中的数据复制到新会话，旧会话ID作废
+func (m *Manager) Login(c *gin.Context, userId string) (*Session, error) {
+	if userId == "" {
+		return nil, errors.New("userId is empty")
+	}
+	old := Get(c)
+	if old == nil {
+		old = m.load(c)
+	}
+	if m.MaxSessions > 0 { //检查在线数和登记新会话之间不能有同一用户的其它登录
+		ctx, cancel := context.WithTimeout(c.Request.Context(), loginLockWait)
+		defer cancel()
+		lease, err := lv_cache.Lock(ctx, userKeyPrefix+userId, loginLockTTL, lv_cache.WithLocker(m.Locker))
+		if err != nil {
+			return nil, err
+		}
+		defer lease.Unlock(context.WithoutCancel(ctx))
+	}
+	if err := m.limitSessions(userId); err != nil {
+		return nil, err
+	}
+	s := m.newSession(c)
+	s.UserId = userId
+	if len(old.Values) > 0 {
+		s.Values = make(map[string]string, len(old.Values))
+		for k, v := range old.Values {
+			s.Values[k] = v
+		}
+	}
+	if !old.isNew {
+		m.destroy(old)
+	}
+	if err := m.save(s); err != nil {
+		return nil, err
+	}
+	if err := m.Cache.HSet(userKeyPrefix+userId, s.ID, strconv.FormatInt(time.Now().UnixNano(), 10)); err != nil {
+		return nil, err
+	}
+	m.Cache.Expire(userKeyPrefix+userId, m.Timeout)
+	m.setCookie(c, s.ID)
+	s.isNew = false
+	c.Set(contextKey, s)
+	return s, nil
+}
+
+// limitSessions 清理已失效的会话，超出同时在线数时按策略踢出最早的会话或拒绝登录
+func (m *Manager) limitSessions(userId string) error {
+	if m.MaxSessions <= 0 {
+		return nil
+	}
+	ids, err := m.UserSessions(userId)
+	if err != nil {
+		return err
+	}
+	if len(ids) < m.MaxSessions {
+		return nil
+	}
+	if m.MaxSessionsPolicy == RejectNew {
+		return ErrTooManySessions
+	}
+	for _, id := range ids[:len(ids)-m.MaxSessions+1] {
+		m.Kick(id)
+	}
+	return nil
+}
+
+// UserSessions 用户当前有效的会话ID，按登录时间从早到晚排列
+func (m *Manager) UserSessions(userId string) ([]string, error) {
+	all, err := m.Cache.HGetAll(userKeyPrefix + userId)
+	if err != nil && !lv_cache.IsNil(err) {
+		return nil, err
+	}
+	ids := make([]string, 0, len(all))
+	for id := range all {
+		if _, err := m.Cache.Get(sessionKeyPrefix + id); err != nil {
+			m.Cache.HDel(userKeyPrefix+userId, id) //会话已过期
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return all[ids[i]] < all[ids[j]] || (all[ids[i]] == all[ids[j]] && ids[i] < ids[j])
+	})
+	return ids, nil
+}
+
+// Kick 使指定会话失效
+func (m *Manager) Kick(id string) error {
+	s, err := m.Find(id)
+	if err != nil {
+		if lv_cache.IsNil(err) {
+			return nil
+		}
+		return err
+	}
+	return m.destroy(s)
+}
+
+// KickUser 踢出用户的所有会话，返回踢出的会话数
+func (m *Manager) KickUser(userId string) (int, error) {
+	ids, err := m.UserSessions(userId)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		m.Cache.Del(sessionKeyPrefix + id)
+	}
+	return len(ids), m.Cache.Del(userKeyPrefix + userId)
+}
+
+// Logout 注销当前会话并清除cookie
+func (m *Manager) Logout(c *gin.Context) error {
+	s := Get(c)
+	if s == nil {
+		s = m.load(c)
+	}
+	m.clearCookie(c)
+	if s.isNew {
+		s.destroyed = true
+		return nil
+	}
+	return m.destroy(s)
+}
+
+func (m *Manager) destroy(s *Session) error {
+	s.destroyed = true
+	if s.UserId != "" {
+		m.Cache.HDel(userKeyPrefix+s.UserId, s.ID)
+	}
+	return m.Cache.Del(sessionKeyPrefix + s.ID)
+}
+
+func (m *Manager) setCookie(c *gin.Context, id string) {
+	http.SetCookie(c.Writer, &http.Cookie{Name: m.CookieName, Value: id, Path: m.Path, Domain: m.Domain,
+		Secure: m.secure(c), HttpOnly: true, SameSite: m.SameSite})
+}
+
+func (m *Manager) clearCookie(c *gin.Context) {
+	http.SetCookie(c.Writer, &http.Cookie{Name: m.CookieName, Value: "", Path: m.Path, Domain: m.Domain, MaxAge: -1,
+		Secure: m.secure(c), HttpOnly: true, SameSite: m.SameSite})
+}
+
+// secure 未配置时按请求是否为https决定，SameSite=None 时浏览器要求必须为Secure
+func (m *Manager) secure(c *gin.Context) bool {
+	switch m.Secure {
+	case "true":
+		return true
+	case "false":
+		return m.SameSite == http.SameSiteNoneMode
+	}
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") ||
+		m.SameSite == http.SameSiteNoneMode
+}
+
+// newSessionId 256位随机数
+func newSessionId() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
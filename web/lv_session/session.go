@@ -0,0 +1,100 @@
+package lv_session
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 保存在 gin.Context 中的会话
+const contextKey = "lv_session"
+
+// Session 服务端会话，数据保存在 ICache 中，cookie 中只有会话ID
+type Session struct {
+	ID        string            `json:"-"`
+	UserId    string            `json:"uid,omitempty"`
+	Values    map[string]string `json:"values,omitempty"`
+	CreatedAt int64             `json:"created"` // unix 秒
+	Ip        string            `json:"ip,omitempty"`
+	UserAgent string            `json:"ua,omitempty"`
+
+	manager   *Manager
+	ctx       *gin.Context
+	isNew     bool // 还未保存过，客户端没有该会话的cookie
+	dirty     bool
+	destroyed bool
+}
+
+// Get 读取会话中的值
+func (s *Session) Get(key string) (string, bool) {
+	v, ok := s.Values[key]
+	return v, ok
+}
+
+// Set 写入会话，请求结束时自动保存
+func (s *Session) Set(key, value string) {
+	if s.Values == nil {
+		s.Values = make(map[string]string)
+	}
+	s.Values[key] = value
+	s.markDirty()
+}
+
+// Delete 删除会话中的值
+func (s *Session) Delete(key string) {
+	if _, ok := s.Values[key]; ok {
+		delete(s.Values, key)
+		s.markDirty()
+	}
+}
+
+// IsLogin 是否已登录
+func (s *Session) IsLogin() bool {
+	return s.UserId != ""
+}
+
+// Created 会话创建时间
+func (s *Session) Created() time.Time {
+	return time.Unix(s.CreatedAt, 0)
+}
+
+// markDirty 新会话第一次写入时下发cookie，cookie 必须在响应写出之前设置
+func (s *Session) markDirty() {
+	s.dirty = true
+	if s.isNew && s.ctx != nil {
+		s.manager.setCookie(s.ctx, s.ID)
+		s.isNew = false
+	}
+}
+
+// Save 立即保存，一般不需要调用，请求结束时会自动保存修改过的会话
+func (s *Session) Save() error {
+	if s.destroyed {
+		return nil
+	}
+	s.dirty = false
+	return s.manager.save(s)
+}
+
+// Get 当前请求的会话，需要先使用 Middleware
+func Get(c *gin.Context) *Session {
+	if v, ok := c.Get(contextKey); ok {
+		return v.(*Session)
+	}
+	return nil
+}
+
+// Login 登录成功后调用，更换会话ID防止会话固定攻击，并校验同一用户的同时在线数
+func Login(c *gin.Context, userId string) (*Session, error) {
+	return Default().Login(c, userId)
+}
+
+// Logout 注销当前会话并清除cookie
+func Logout(c *gin.Context) error {
+	return Default().Logout(c)
+}
+
+// KickUser 踢出用户的所有会话，返回踢出的会话数
+func KickUser(userId string) (int, error) {
+	return Default().KickUser(userId)
+}
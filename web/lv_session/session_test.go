@@ -0,0 +1,118 @@
+package lv_session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lostvip-com/lv_framework/lv_cache/lv_ram"
+)
+
+func newTestEngine(m *Manager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/set", func(c *gin.Context) {
+		Get(c).Set("captcha", "1234")
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/get", func(c *gin.Context) {
+		v, _ := Get(c).Get("captcha")
+		c.String(http.StatusOK, Get(c).UserId+"|"+v)
+	})
+	r.POST("/login", func(c *gin.Context) {
+		if _, err := m.Login(c, "admin"); err != nil {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func do(r *gin.Engine, method, path, sid string) (*httptest.ResponseRecorder, string) {
+	req := httptest.NewRequest(method, path, nil)
+	if sid != "" {
+		req.AddCookie(&http.Cookie{Name: "LVSESSIONID", Value: sid})
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "LVSESSIONID" {
+			sid = c.Value
+		}
+	}
+	return w, sid
+}
+
+func TestSessionLogin(t *testing.T) {
+	m := NewManager(lv_ram.NewRamCacheClient(), Options{Timeout: time.Minute, MaxSessions: 1})
+	r := newTestEngine(m)
+	if _, sid := do(r, "GET", "/get", ""); sid != "" {
+		t.Fatal("session created without writes")
+	}
+	w, anon := do(r, "GET", "/set", "")
+	if anon == "" || w.Header().Get("Set-Cookie") == "" {
+		t.Fatal("no cookie")
+	}
+	_, first := do(r, "POST", "/login", anon)
+	if first == anon {
+		t.Fatal("session id not rotated on login")
+	}
+	if w, _ := do(r, "GET", "/get", first); w.Body.String() != "admin|1234" {
+		t.Fatal(w.Body.String())
+	}
+	if w, _ := do(r, "GET", "/get", anon); w.Body.String() != "|" {
+		t.Fatal("old session still valid", w.Body.String())
+	}
+
+	_, second := do(r, "POST", "/login", "")
+	if w, _ := do(r, "GET", "/get", first); w.Body.String() != "|" { //超出同时在线数，最早的会话被踢出
+		t.Fatal(w.Body.String())
+	}
+	m.MaxSessionsPolicy = RejectNew
+	if w, _ := do(r, "POST", "/login", ""); w.Code != http.StatusForbidden {
+		t.Fatal(w.Code)
+	}
+	if n, _ := m.KickUser("admin"); n != 1 {
+		t.Fatal(n)
+	}
+	if w, _ := do(r, "GET", "/get", second); w.Body.String() != "|" {
+		t.Fatal(w.Body.String())
+	}
+}
+
+// slowCache 放大检查在线数与登记会话之间的时间窗口
+type slowCache struct {
+	*lv_ram.RamCacheClient
+}
+
+func (c slowCache) HGetAll(key string) (map[string]string, error) {
+	m, err := c.RamCacheClient.HGetAll(key)
+	time.Sleep(10 * time.Millisecond)
+	return m, err
+}
+
+func TestSessionConcurrentLogin(t *testing.T) {
+	m := NewManager(slowCache{lv_ram.NewRamCacheClient()}, Options{Timeout: time.Minute, MaxSessions: 1, MaxSessionsPolicy: RejectNew})
+	r := newTestEngine(m)
+	var ok int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w, _ := do(r, "POST", "/login", ""); w.Code == http.StatusOK {
+				atomic.AddInt32(&ok, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if ids, _ := m.UserSessions("admin"); ok != 1 || len(ids) != 1 { //并发登录不能超出同时在线数
+		t.Fatal(ok, ids)
+	}
+}